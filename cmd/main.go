@@ -7,10 +7,18 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/AugustSerenity/marketplace/internal/blob"
 	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/contentpolicy"
+	"github.com/AugustSerenity/marketplace/internal/events"
 	"github.com/AugustSerenity/marketplace/internal/handler"
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
+	"github.com/AugustSerenity/marketplace/internal/mail"
+	"github.com/AugustSerenity/marketplace/internal/notify"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
 	"github.com/AugustSerenity/marketplace/internal/service"
 	"github.com/AugustSerenity/marketplace/internal/storage"
 )
@@ -30,17 +38,98 @@ func main() {
 
 	storage := storage.New(db)
 
-	srv := service.New(storage, cfg.Secret)
+	images, err := blob.NewLocalStore(cfg.Images.Dir, cfg.Images.BaseURL)
+	if err != nil {
+		log.Fatalf("failed to init image store: %v", err)
+	}
+
+	var revoked revocation.Store = storage
+	if cfg.Auth.Revocation == "memory" {
+		revoked = revocation.NewMemoryStore(cfg.Auth.AccessTTL)
+	}
+
+	keyring, err := jwtkeys.NewKeyring(cfg.Auth.KeysDir, cfg.Auth.Algorithm)
+	if err != nil {
+		log.Fatalf("failed to init signing keys: %v", err)
+	}
+
+	policy, err := contentpolicy.Load(cfg.Content.PolicyFile)
+	if err != nil {
+		log.Fatalf("failed to load content policy: %v", err)
+	}
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			if err := policy.Reload(); err != nil {
+				log.Printf("content policy reload failed, keeping previous rules: %v", err)
+				continue
+			}
+			log.Printf("content policy reloaded: %d rules", policy.Len())
+		}
+	}()
+
+	var notifier notify.Notifier = notify.NewLogNotifier(nil)
+	if cfg.Notifications.Notifier == "file" {
+		notifier, err = notify.NewFileNotifier(cfg.Notifications.File)
+		if err != nil {
+			log.Fatalf("failed to init notifier: %v", err)
+		}
+	}
+
+	var mailer mail.Mailer
+	switch cfg.Mail.Mailer {
+	case "smtp":
+		mailer = mail.NewSMTPMailer(cfg.Mail.SMTP.Host, cfg.Mail.SMTP.Port, cfg.Mail.SMTP.Username, cfg.Mail.SMTP.Password, cfg.Mail.From)
+	default:
+		mailer, err = mail.NewOutboxMailer(cfg.Mail.Outbox)
+		if err != nil {
+			log.Fatalf("failed to init mailer: %v", err)
+		}
+	}
+
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go keyring.Run(backgroundCtx, cfg.Auth.KeyRotation, cfg.Auth.AccessTTL)
+
+	hub := events.NewHub(cfg.Events.HistorySize, cfg.Events.HistoryTTL)
+	go hub.Run(backgroundCtx)
+
+	srv := service.New(
+		storage,
+		cfg.Secret,
+		service.WithImages(images, cfg.Images),
+		service.WithAdmins(cfg.AdminIDs...),
+		service.WithTitles(cfg.Titles),
+		service.WithModeration(cfg.Moderation),
+		service.WithReports(cfg.Reports),
+		service.WithContentPolicy(policy),
+		service.WithAuth(cfg.Auth),
+		service.WithRevocation(revoked),
+		service.WithSigner(keyring),
+		service.WithEvents(hub),
+		service.WithNotifier(notifier),
+		service.WithMailer(mailer),
+	)
+
+	h := handler.New(srv, keyring.Keyfunc,
+		handler.WithRevocation(revoked),
+		handler.WithEvents(hub, cfg.Events.Heartbeat),
+	)
 
-	h := handler.New(srv, cfg.Secret)
+	router := http.NewServeMux()
+	router.Handle("/", h.Route())
+	router.Handle("GET /static/images/", http.StripPrefix("/static/images/", images.Handler()))
 
 	s := http.Server{
 		Addr:         cfg.Address,
-		Handler:      h.Route(),
+		Handler:      router,
 		IdleTimeout:  cfg.IdleTimeout,
 		ReadTimeout:  cfg.Timeout,
 		WriteTimeout: cfg.Timeout,
 	}
+	s.RegisterOnShutdown(hub.Close)
 	go func() {
 		err := s.ListenAndServe()
 		if err != nil {
@@ -59,7 +148,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	err := s.Shutdown(ctx)
+	err = s.Shutdown(ctx)
 	if err != nil {
 		log.Println("shutdown server error: %w", err)
 	}
@@ -0,0 +1,14 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+type Store interface {
+	Put(ctx context.Context, key, contentType string, r io.Reader) (string, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,47 @@
+package blob
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir, "http://localhost/static/images/")
+	require.NoError(t, err)
+
+	url, err := store.Put(ctx, "abc.png", "image/png", strings.NewReader("png"))
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost/static/images/abc.png", url)
+
+	data, err := os.ReadFile(filepath.Join(dir, "abc.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+
+	w := httptest.NewRecorder()
+	store.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/abc.png", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "png", w.Body.String())
+
+	w = httptest.NewRecorder()
+	store.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code, "directory listing is hidden")
+
+	for _, key := range []string{"", "../escape.png", "nested/key.png", ".hidden"} {
+		_, err := store.Put(ctx, key, "image/png", strings.NewReader("x"))
+		assert.ErrorIs(t, err, ErrInvalidKey, "key %q", key)
+	}
+
+	require.NoError(t, store.Delete(ctx, "abc.png"))
+	_, err = os.Stat(filepath.Join(dir, "abc.png"))
+	assert.True(t, os.IsNotExist(err), "file is removed, got %v", err)
+}
@@ -8,9 +8,19 @@ import (
 )
 
 type Config struct {
-	Server `yaml:"server"`
-	DB     `yaml:"db"`
-	Secret string `yaml:"secret"`
+	Server        `yaml:"server"`
+	DB            `yaml:"db"`
+	Images        `yaml:"images"`
+	Titles        `yaml:"titles"`
+	Auth          `yaml:"auth"`
+	Moderation    `yaml:"moderation"`
+	Reports       `yaml:"reports"`
+	Content       `yaml:"content_policy" mapstructure:"content_policy"`
+	Events        `yaml:"events"`
+	Notifications `yaml:"notifications"`
+	Mail          `yaml:"mail"`
+	Secret        string  `yaml:"secret"`
+	AdminIDs      []int64 `yaml:"admin_ids" mapstructure:"admin_ids"`
 }
 
 type Server struct {
@@ -28,6 +38,70 @@ type DB struct {
 	Password string `yaml:"password" env-default:"postgres"`
 }
 
+type Images struct {
+	Dir               string `yaml:"dir" mapstructure:"dir" env-default:"uploads"`
+	BaseURL           string `yaml:"base_url" mapstructure:"base_url" env-default:"http://localhost:8080/static/images"`
+	MaxBytes          int64  `yaml:"max_bytes" mapstructure:"max_bytes" env-default:"5242880"`
+	MaxWidth          int    `yaml:"max_width" mapstructure:"max_width" env-default:"4096"`
+	MaxHeight         int    `yaml:"max_height" mapstructure:"max_height" env-default:"4096"`
+	AllowExternalURLs bool   `yaml:"allow_external_urls" mapstructure:"allow_external_urls"`
+}
+
+type Auth struct {
+	AccessTTL            time.Duration `yaml:"access_ttl" mapstructure:"access_ttl" env-default:"15m"`
+	RefreshTTL           time.Duration `yaml:"refresh_ttl" mapstructure:"refresh_ttl" env-default:"720h"`
+	Revocation           string        `yaml:"revocation" mapstructure:"revocation" env-default:"postgres"`
+	KeysDir              string        `yaml:"keys_dir" mapstructure:"keys_dir" env-default:"keys"`
+	Algorithm            string        `yaml:"algorithm" mapstructure:"algorithm" env-default:"EdDSA"`
+	KeyRotation          time.Duration `yaml:"key_rotation" mapstructure:"key_rotation" env-default:"720h"`
+	PasswordResetTTL     time.Duration `yaml:"password_reset_ttl" mapstructure:"password_reset_ttl" env-default:"1h"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl" mapstructure:"email_verification_ttl" env-default:"48h"`
+	RequireVerifiedEmail bool          `yaml:"require_verified_email" mapstructure:"require_verified_email"`
+}
+
+type Moderation struct {
+	Categories []string `yaml:"categories" mapstructure:"categories"`
+}
+
+type Reports struct {
+	HideThreshold int `yaml:"hide_threshold" mapstructure:"hide_threshold" env-default:"3"`
+}
+
+type Content struct {
+	PolicyFile string `yaml:"policy_file" mapstructure:"policy_file" env-default:"config/content_policy.yaml"`
+}
+
+type Events struct {
+	Heartbeat   time.Duration `yaml:"heartbeat" mapstructure:"heartbeat" env-default:"25s"`
+	HistorySize int           `yaml:"history_size" mapstructure:"history_size" env-default:"100"`
+	HistoryTTL  time.Duration `yaml:"history_ttl" mapstructure:"history_ttl" env-default:"10m"`
+}
+
+type Notifications struct {
+	Notifier string `yaml:"notifier" mapstructure:"notifier" env-default:"log"`
+	File     string `yaml:"file" mapstructure:"file" env-default:"var/notifications.jsonl"`
+}
+
+type Mail struct {
+	Mailer string `yaml:"mailer" mapstructure:"mailer" env-default:"outbox"`
+	Outbox string `yaml:"outbox" mapstructure:"outbox" env-default:"var/outbox.jsonl"`
+	From   string `yaml:"from" mapstructure:"from" env-default:"no-reply@marketplace.local"`
+	SMTP   `yaml:"smtp" mapstructure:"smtp"`
+}
+
+type SMTP struct {
+	Host     string `yaml:"host" mapstructure:"host"`
+	Port     int    `yaml:"port" mapstructure:"port" env-default:"587"`
+	Username string `yaml:"username" mapstructure:"username"`
+	Password string `yaml:"password" mapstructure:"password"`
+}
+
+type Titles struct {
+	MaxLength    int    `yaml:"max_length" mapstructure:"max_length" env-default:"100"`
+	Punctuation  string `yaml:"punctuation" mapstructure:"punctuation"`
+	AllowSymbols bool   `yaml:"allow_symbols" mapstructure:"allow_symbols"`
+}
+
 func ParseConfig(path string) *Config {
 	var cfg *Config
 
@@ -0,0 +1,319 @@
+package contentpolicy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	ActionReject   = "reject"
+	ActionModerate = "moderate"
+	ActionMask     = "mask"
+
+	FieldTitle       = "title"
+	FieldDescription = "description"
+)
+
+var ErrInvalidRule = errors.New("invalid content rule")
+
+var actionWeight = map[string]int{
+	ActionMask:     1,
+	ActionModerate: 2,
+	ActionReject:   3,
+}
+
+type Rule struct {
+	Name     string   `yaml:"name" mapstructure:"name"`
+	Terms    []string `yaml:"terms" mapstructure:"terms"`
+	Patterns []string `yaml:"patterns" mapstructure:"patterns"`
+	Action   string   `yaml:"action" mapstructure:"action"`
+	Fields   []string `yaml:"fields" mapstructure:"fields"`
+}
+
+type Match struct {
+	Rule   string
+	Action string
+	Field  string
+	Text   string
+}
+
+type Result struct {
+	Title       string
+	Description string
+	Matches     []Match
+}
+
+func (r Result) Action() string {
+	action := ""
+	for _, m := range r.Matches {
+		if actionWeight[m.Action] > actionWeight[action] {
+			action = m.Action
+		}
+	}
+	return action
+}
+
+func (r Result) First(action string) (Match, bool) {
+	for _, m := range r.Matches {
+		if m.Action == action {
+			return m, true
+		}
+	}
+	return Match{}, false
+}
+
+func (r Result) Rules(action string) []string {
+	seen := make(map[string]bool)
+	var rules []string
+	for _, m := range r.Matches {
+		if m.Action == action && !seen[m.Rule] {
+			seen[m.Rule] = true
+			rules = append(rules, m.Rule)
+		}
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+type compiledRule struct {
+	name     string
+	action   string
+	fields   map[string]bool
+	terms    *regexp.Regexp
+	patterns []*regexp.Regexp
+}
+
+type Policy struct {
+	mu    sync.RWMutex
+	path  string
+	rules []compiledRule
+}
+
+func New(rules []Rule) (*Policy, error) {
+	compiled, err := compile(rules)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{rules: compiled}, nil
+}
+
+func Load(path string) (*Policy, error) {
+	p := &Policy{path: path}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Policy) Reload() error {
+	if p.path == "" {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(p.path)
+	if err := v.ReadInConfig(); err != nil {
+		return err
+	}
+
+	var file struct {
+		Rules []Rule `mapstructure:"rules"`
+	}
+	if err := v.Unmarshal(&file); err != nil {
+		return err
+	}
+
+	compiled, err := compile(file.Rules)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.rules = compiled
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Policy) Len() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.rules)
+}
+
+func (p *Policy) Check(title, description string) Result {
+	p.mu.RLock()
+	rules := p.rules
+	p.mu.RUnlock()
+
+	res := Result{Title: title, Description: description}
+	for _, rule := range rules {
+		if rule.fields[FieldTitle] {
+			res.Title = rule.apply(FieldTitle, res.Title, &res.Matches)
+		}
+		if rule.fields[FieldDescription] {
+			res.Description = rule.apply(FieldDescription, res.Description, &res.Matches)
+		}
+	}
+	return res
+}
+
+func (r compiledRule) apply(field, text string, matches *[]Match) string {
+	if r.terms != nil {
+		text = r.record(field, text, findTerms(r.terms, text), matches)
+	}
+	for _, re := range r.patterns {
+		text = r.record(field, text, re.FindAllStringSubmatchIndex(text, -1), matches)
+	}
+	return text
+}
+
+func (r compiledRule) record(field, text string, locs [][]int, matches *[]Match) string {
+	if len(locs) == 0 {
+		return text
+	}
+
+	for _, loc := range locs {
+		start, end := matchBounds(loc)
+		*matches = append(*matches, Match{Rule: r.name, Action: r.action, Field: field, Text: text[start:end]})
+	}
+	if r.action == ActionMask {
+		text = mask(text, locs)
+	}
+	return text
+}
+
+func findTerms(re *regexp.Regexp, text string) [][]int {
+	var locs [][]int
+	for pos := 0; pos < len(text); {
+		loc := re.FindStringIndex(text[pos:])
+		if loc == nil {
+			break
+		}
+		start, end := pos+loc[0], pos+loc[1]
+		if isWholeWord(text, start, end) {
+			locs = append(locs, []int{start, end})
+			pos = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[start:])
+		pos = start + size
+	}
+	return locs
+}
+
+func isWholeWord(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+func matchBounds(loc []int) (int, int) {
+	if len(loc) >= 4 && loc[2] >= 0 {
+		return loc[2], loc[3]
+	}
+	return loc[0], loc[1]
+}
+
+func mask(text string, locs [][]int) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	last := 0
+	for _, loc := range locs {
+		start, end := matchBounds(loc)
+		b.WriteString(text[last:start])
+		for range text[start:end] {
+			b.WriteRune('*')
+		}
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func compile(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	names := make(map[string]bool, len(rules))
+
+	for i, rule := range rules {
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: rule #%d has no name", ErrInvalidRule, i+1)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("%w: duplicate rule %q", ErrInvalidRule, name)
+		}
+		names[name] = true
+
+		action := strings.ToLower(strings.TrimSpace(rule.Action))
+		if action == "" {
+			action = ActionReject
+		}
+		if _, ok := actionWeight[action]; !ok {
+			return nil, fmt.Errorf("%w: rule %q has unknown action %q", ErrInvalidRule, name, rule.Action)
+		}
+
+		c := compiledRule{name: name, action: action, fields: make(map[string]bool)}
+		if len(rule.Fields) == 0 {
+			c.fields[FieldTitle] = true
+			c.fields[FieldDescription] = true
+		}
+		for _, field := range rule.Fields {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if field != FieldTitle && field != FieldDescription {
+				return nil, fmt.Errorf("%w: rule %q has unknown field %q", ErrInvalidRule, name, field)
+			}
+			c.fields[field] = true
+		}
+
+		if len(rule.Terms) > 0 {
+			for _, term := range rule.Terms {
+				if strings.TrimSpace(term) == "" {
+					return nil, fmt.Errorf("%w: rule %q has an empty term", ErrInvalidRule, name)
+				}
+			}
+			c.terms = termsPattern(rule.Terms)
+		}
+		for _, pattern := range rule.Patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule %q: %v", ErrInvalidRule, name, err)
+			}
+			c.patterns = append(c.patterns, re)
+		}
+		if c.terms == nil && len(c.patterns) == 0 {
+			return nil, fmt.Errorf("%w: rule %q has no terms or patterns", ErrInvalidRule, name)
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+func termsPattern(terms []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		term = strings.Join(strings.Fields(term), " ")
+		quoted = append(quoted, strings.ReplaceAll(regexp.QuoteMeta(term), " ", `\s+`))
+	}
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(quoted, "|") + `)`)
+}
@@ -0,0 +1,157 @@
+package contentpolicy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_DefaultRules(t *testing.T) {
+	p, err := Load("../../config/content_policy.yaml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		wantAction  string
+		wantRule    string
+		wantField   string
+		wantDesc    string
+	}{
+		{name: "clean text", title: "Велосипед", description: "Почти новый, 21 скорость"},
+		{name: "price is not a phone", title: "Ноутбук", description: "Цена 45 000, торг"},
+		{name: "phone with separators", title: "Велосипед", description: "Звоните +7 (912) 345-67-89", wantAction: ActionReject, wantRule: "phone-number", wantField: FieldDescription},
+		{name: "phone with fullwidth digits", title: "Велосипед", description: "８９１２３４５６７８９", wantAction: ActionReject, wantRule: "phone-number", wantField: FieldDescription},
+		{name: "phone in title", title: "Диван 89123456789", description: "Самовывоз", wantAction: ActionReject, wantRule: "phone-number", wantField: FieldTitle},
+		{name: "email", title: "Шкаф", description: "пишите на seller@mail.ru", wantAction: ActionReject, wantRule: "email", wantField: FieldDescription},
+		{name: "telegram link", title: "Шкаф", description: "всё в t.me/seller_42", wantAction: ActionReject, wantRule: "messenger-link", wantField: FieldDescription},
+		{name: "telegram handle", title: "Шкаф", description: "мой ник @seller_42", wantAction: ActionReject, wantRule: "messenger-link", wantField: FieldDescription},
+		{name: "messenger mention", title: "Шкаф", description: "Отвечу в Телеграм", wantAction: ActionModerate, wantRule: "messenger-mention", wantField: FieldDescription},
+		{name: "term inside a longer word", title: "Шкаф", description: "телеграмма от бабушки"},
+		{name: "banned phrase across spaces", title: "Купить   диплом", description: "быстро", wantAction: ActionReject, wantRule: "prohibited-goods", wantField: FieldTitle},
+		{
+			name:        "external link is masked",
+			title:       "Шкаф",
+			description: "фото тут https://example.com/x.jpg",
+			wantAction:  ActionMask,
+			wantRule:    "external-link",
+			wantField:   FieldDescription,
+			wantDesc:    "фото тут *************************",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := p.Check(tt.title, tt.description)
+
+			assert.Equal(t, tt.wantAction, res.Action())
+			if tt.wantAction == "" {
+				assert.Empty(t, res.Matches)
+				return
+			}
+
+			m, ok := res.First(tt.wantAction)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantRule, m.Rule)
+			assert.Equal(t, tt.wantField, m.Field)
+			if tt.wantDesc != "" {
+				assert.Equal(t, tt.wantDesc, res.Description)
+			}
+		})
+	}
+}
+
+func TestPolicy_StrongestActionWins(t *testing.T) {
+	p, err := New([]Rule{
+		{Name: "mask-word", Terms: []string{"дешево"}, Action: ActionMask},
+		{Name: "moderate-word", Terms: []string{"оригинал"}, Action: ActionModerate},
+		{Name: "reject-word", Terms: []string{"реплика"}, Action: ActionReject, Fields: []string{FieldTitle}},
+	})
+	require.NoError(t, err)
+
+	res := p.Check("Кроссовки оригинал", "Дешево, реплика")
+	assert.Equal(t, ActionModerate, res.Action())
+	assert.Equal(t, "******, реплика", res.Description)
+	assert.Equal(t, []string{"moderate-word"}, res.Rules(ActionModerate))
+
+	res = p.Check("Реплика", "")
+	assert.Equal(t, ActionReject, res.Action())
+}
+
+func TestPolicy_AdjacentTerms(t *testing.T) {
+	p, err := New([]Rule{
+		{Name: "spam", Terms: []string{"дешево", "срочно", "sale now"}, Action: ActionMask},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		description string
+		want        string
+		matches     int
+	}{
+		{name: "separated by a space", description: "дешево срочно", want: "****** ******", matches: 2},
+		{name: "separated by punctuation", description: "Дешево,срочно!", want: "******,******!", matches: 2},
+		{name: "repeated term", description: "срочно срочно срочно", want: "****** ****** ******", matches: 3},
+		{name: "multi-word term", description: "sale  now, срочно", want: "*********, ******", matches: 2},
+		{name: "part of a word", description: "несрочно дешевого", want: "несрочно дешевого"},
+		{name: "term after a rejected candidate", description: "xсрочно срочно", want: "xсрочно ******", matches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := p.Check("", tt.description)
+			assert.Equal(t, tt.want, res.Description)
+			assert.Len(t, res.Matches, tt.matches)
+		})
+	}
+}
+
+func TestPolicy_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+	}{
+		{name: "missing name", rules: []Rule{{Terms: []string{"x"}}}},
+		{name: "duplicate name", rules: []Rule{{Name: "a", Terms: []string{"x"}}, {Name: "a", Terms: []string{"y"}}}},
+		{name: "unknown action", rules: []Rule{{Name: "a", Terms: []string{"x"}, Action: "ban"}}},
+		{name: "unknown field", rules: []Rule{{Name: "a", Terms: []string{"x"}, Fields: []string{"price"}}}},
+		{name: "bad pattern", rules: []Rule{{Name: "a", Patterns: []string{"("}}}},
+		{name: "nothing to match", rules: []Rule{{Name: "a"}}},
+		{name: "empty term", rules: []Rule{{Name: "a", Terms: []string{"x", ""}}}},
+		{name: "whitespace term", rules: []Rule{{Name: "a", Terms: []string{" \t "}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.rules)
+			assert.ErrorIs(t, err, ErrInvalidRule)
+		})
+	}
+}
+
+func TestPolicy_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: first\n    terms: [\"alpha\"]\n"), 0o644))
+
+	p, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, ActionReject, p.Check("alpha", "").Action())
+
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: second\n    terms: [\"beta\"]\n    action: moderate\n"), 0o644))
+	require.NoError(t, p.Reload())
+	assert.Empty(t, p.Check("alpha", "").Matches)
+	assert.Equal(t, ActionModerate, p.Check("beta", "").Action())
+
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: broken\n    patterns: [\"(\"]\n"), 0o644))
+	assert.ErrorIs(t, p.Reload(), ErrInvalidRule)
+	assert.Equal(t, ActionModerate, p.Check("beta", "").Action())
+
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - name: blank\n    terms: [\"gamma\", \" \"]\n"), 0o644))
+	assert.ErrorIs(t, p.Reload(), ErrInvalidRule, "empty terms are rejected on reload")
+	assert.Equal(t, ActionModerate, p.Check("beta", "").Action())
+}
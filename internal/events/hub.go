@@ -0,0 +1,192 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	TypeMessage   = "message"
+	TypeAdStatus  = "ad_status"
+	TypePriceDrop = "price_drop"
+
+	defaultHistorySize = 100
+	defaultHistoryTTL  = 10 * time.Minute
+	subscriberBuffer   = 16
+	pruneInterval      = time.Minute
+)
+
+type Event struct {
+	ID        uint64
+	Type      string
+	Data      json.RawMessage
+	CreatedAt time.Time
+}
+
+type Subscription struct {
+	C      <-chan Event
+	events chan Event
+	hub    *Hub
+	userID int64
+	once   sync.Once
+}
+
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+type Hub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	historySize int
+	historyTTL  time.Duration
+	history     map[int64][]Event
+	subscribers map[int64]map[*Subscription]struct{}
+	closed      bool
+	now         func() time.Time
+}
+
+func NewHub(historySize int, historyTTL time.Duration) *Hub {
+	if historySize <= 0 {
+		historySize = defaultHistorySize
+	}
+	if historyTTL <= 0 {
+		historyTTL = defaultHistoryTTL
+	}
+	return &Hub{
+		lastID:      uint64(time.Now().UnixMicro()),
+		historySize: historySize,
+		historyTTL:  historyTTL,
+		history:     make(map[int64][]Event),
+		subscribers: make(map[int64]map[*Subscription]struct{}),
+		now:         time.Now,
+	}
+}
+
+func (h *Hub) Publish(userID int64, eventType string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("events: marshal %s payload: %v", eventType, err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.lastID++
+	event := Event{ID: h.lastID, Type: eventType, Data: data, CreatedAt: h.now()}
+
+	history := append(h.history[userID], event)
+	if len(history) > h.historySize {
+		history = history[len(history)-h.historySize:]
+	}
+	h.history[userID] = history
+
+	for sub := range h.subscribers[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			// A subscriber that cannot keep up is disconnected and
+			// catches up through Last-Event-ID when it reconnects.
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *Hub) Subscribe(userID int64, lastEventID uint64) (*Subscription, []Event) {
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: events, events: events, hub: h, userID: userID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		sub.once.Do(func() { close(events) })
+		return sub, nil
+	}
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	h.subscribers[userID][sub] = struct{}{}
+
+	var missed []Event
+	if lastEventID > 0 {
+		cutoff := h.now().Add(-h.historyTTL)
+		for _, event := range h.history[userID] {
+			if event.ID > lastEventID && event.CreatedAt.After(cutoff) {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	return sub, missed
+}
+
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subscribers {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *Hub) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.prune()
+		}
+	}
+}
+
+func (h *Hub) prune() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := h.now().Add(-h.historyTTL)
+	for userID, history := range h.history {
+		i := 0
+		for i < len(history) && !history[i].CreatedAt.After(cutoff) {
+			i++
+		}
+		if i == len(history) {
+			delete(h.history, userID)
+			continue
+		}
+		h.history[userID] = history[i:]
+	}
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	sub.once.Do(func() {
+		subs := h.subscribers[sub.userID]
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(h.subscribers, sub.userID)
+		}
+		close(sub.events)
+	})
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receive(t *testing.T, sub *Subscription) Event {
+	t.Helper()
+	select {
+	case event, ok := <-sub.C:
+		require.True(t, ok, "subscription closed")
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func TestHub_FanOut(t *testing.T) {
+	hub := NewHub(10, time.Minute)
+
+	first, _ := hub.Subscribe(1, 0)
+	defer first.Close()
+	second, _ := hub.Subscribe(1, 0)
+	defer second.Close()
+	other, _ := hub.Subscribe(2, 0)
+	defer other.Close()
+
+	hub.Publish(1, TypeAdStatus, AdStatusPayload{AdID: 7, Status: "published"})
+
+	for _, sub := range []*Subscription{first, second} {
+		event := receive(t, sub)
+		assert.Equal(t, TypeAdStatus, event.Type)
+		var payload AdStatusPayload
+		require.NoError(t, json.Unmarshal(event.Data, &payload))
+		assert.Equal(t, int64(7), payload.AdID)
+	}
+
+	select {
+	case event := <-other.C:
+		t.Fatalf("unexpected event for another user: %+v", event)
+	default:
+	}
+}
+
+func TestHub_Replay(t *testing.T) {
+	hub := NewHub(3, time.Minute)
+
+	for i := 0; i < 5; i++ {
+		hub.Publish(1, TypeMessage, MessagePayload{MessageID: int64(i)})
+	}
+
+	sub, missed := hub.Subscribe(1, 0)
+	sub.Close()
+	assert.Empty(t, missed, "a fresh connection gets no history")
+
+	first := hub.history[1][0].ID
+	sub, missed = hub.Subscribe(1, first)
+	defer sub.Close()
+	require.Len(t, missed, 2, "history is capped and replayed after Last-Event-ID")
+	assert.Greater(t, missed[0].ID, first)
+	assert.Less(t, missed[0].ID, missed[1].ID)
+}
+
+func TestHub_HistoryTTL(t *testing.T) {
+	hub := NewHub(10, time.Minute)
+	now := time.Now()
+	hub.now = func() time.Time { return now }
+
+	hub.Publish(1, TypeMessage, MessagePayload{MessageID: 1})
+	hub.Publish(1, TypeMessage, MessagePayload{MessageID: 2})
+	lastID := hub.lastID
+
+	now = now.Add(2 * time.Minute)
+	hub.Publish(1, TypeMessage, MessagePayload{MessageID: 3})
+
+	sub, missed := hub.Subscribe(1, 1)
+	sub.Close()
+	require.Len(t, missed, 1)
+	assert.Equal(t, lastID+1, missed[0].ID)
+
+	hub.prune()
+	assert.Len(t, hub.history[1], 1)
+
+	now = now.Add(2 * time.Minute)
+	hub.prune()
+	assert.NotContains(t, hub.history, int64(1))
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub(100, time.Minute)
+	sub, _ := hub.Subscribe(1, 0)
+
+	for i := 0; i < subscriberBuffer+1; i++ {
+		hub.Publish(1, TypeMessage, MessagePayload{MessageID: int64(i)})
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+	assert.NotContains(t, hub.subscribers, int64(1))
+
+	sub.Close()
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(10, time.Minute)
+	sub, _ := hub.Subscribe(1, 0)
+
+	hub.Close()
+	_, ok := <-sub.C
+	assert.False(t, ok)
+
+	late, _ := hub.Subscribe(1, 0)
+	_, ok = <-late.C
+	assert.False(t, ok)
+	late.Close()
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
+type MessagePayload struct {
+	ThreadID  int64     `json:"thread_id"`
+	AdID      int64     `json:"ad_id"`
+	MessageID int64     `json:"message_id"`
+	SenderID  int64     `json:"sender_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AdStatusPayload struct {
+	AdID   int64  `json:"ad_id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type PriceDropPayload struct {
+	AdID     int64        `json:"ad_id"`
+	Title    string       `json:"title"`
+	OldPrice money.Amount `json:"old_price"`
+	NewPrice money.Amount `json:"new_price"`
+	Currency string       `json:"currency"`
+}
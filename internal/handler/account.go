@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req auth.PasswordChangeRequest
+	if !h.decodeAccountRequest(w, r, http.MethodPost, &req) {
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.ChangePassword(r.Context(), req.CurrentPassword, req.NewPassword, userID); err != nil {
+		writeAccountError(w, err, "Failed to change password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req auth.PasswordResetRequest
+	if !h.decodeAccountRequest(w, r, http.MethodPost, &req) {
+		return
+	}
+
+	if err := h.service.RequestPasswordReset(r.Context(), req.Login); err != nil {
+		writeAccountError(w, err, "Failed to request password reset")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req auth.PasswordResetConfirmRequest
+	if !h.decodeAccountRequest(w, r, http.MethodPost, &req) {
+		return
+	}
+
+	if err := h.service.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeAccountError(w, err, "Failed to reset password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	var req user.DeleteRequest
+	if !h.decodeAccountRequest(w, r, http.MethodDelete, &req) {
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.DeleteAccount(r.Context(), req.Password, userID); err != nil {
+		writeAccountError(w, err, "Failed to delete account")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) SetEmail(w http.ResponseWriter, r *http.Request) {
+	var req user.EmailRequest
+	if !h.decodeAccountRequest(w, r, http.MethodPost, &req) {
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.SetEmail(r.Context(), req.Email, userID); err != nil {
+		writeAccountError(w, err, "Failed to send verification email")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req auth.EmailVerificationRequest
+	if !h.decodeAccountRequest(w, r, http.MethodPost, &req) {
+		return
+	}
+
+	if err := h.service.VerifyEmail(r.Context(), req.Token); err != nil {
+		writeAccountError(w, err, "Failed to verify email")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) decodeAccountRequest(w http.ResponseWriter, r *http.Request, method string, req any) bool {
+	defer r.Body.Close()
+
+	if r.Method != method {
+		http.Error(w, "Only "+method+" method is allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return false
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func writeAccountError(w http.ResponseWriter, err error, fallback string) {
+	var fieldErr *model.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		writeAdError(w, err, fallback)
+	case errors.Is(err, model.ErrWrongPassword):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, model.ErrInvalidResetToken), errors.Is(err, model.ErrInvalidVerificationToken):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, model.ErrEmailTaken), errors.Is(err, model.ErrEmailAlreadyVerified):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, model.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, model.ErrPasswordResetDisabled), errors.Is(err, model.ErrEmailVerificationDisabled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		writeAuthError(w, err, fallback)
+	}
+}
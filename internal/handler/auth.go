@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(h.service.PublicKeys())
+}
+
+func (h *Handler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeRefreshRequest(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.service.RefreshTokens(r.Context(), req.RefreshToken)
+	if err != nil {
+		writeAuthError(w, err, "Failed to refresh token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeRefreshRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Logout(r.Context(), req.RefreshToken); err != nil {
+		writeAuthError(w, err, "Failed to log out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tokenID, _ := r.Context().Value("tokenID").(string)
+	expiresAt, _ := r.Context().Value("tokenExpiresAt").(time.Time)
+	if tokenID == "" {
+		http.Error(w, "Token cannot be revoked", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeToken(r.Context(), tokenID, expiresAt); err != nil {
+		writeAuthError(w, err, "Failed to revoke token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeAllTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.RevokeAllTokens(r.Context(), userID); err != nil {
+		writeAuthError(w, err, "Failed to revoke tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) RevokeUserTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	targetID, err := parseUserID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeUserTokens(r.Context(), targetID, userID); err != nil {
+		writeAuthError(w, err, "Failed to revoke tokens")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) decodeRefreshRequest(w http.ResponseWriter, r *http.Request) (auth.RefreshRequest, bool) {
+	defer r.Body.Close()
+
+	var req auth.RefreshRequest
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return req, false
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return req, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return req, false
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return req, false
+	}
+
+	return req, true
+}
+
+func writeAuthError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrInvalidRefreshToken), errors.Is(err, model.ErrRefreshTokenReused):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, model.ErrNotAdmin):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, context.Canceled):
+		http.Error(w, "Request Timeout", http.StatusRequestTimeout)
+	default:
+		http.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/category"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tree, err := h.service.GetCategoryTree(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to fetch categories", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toCategoryNodes(tree))
+}
+
+func toCategoryNodes(categories []*model.Category) []category.Node {
+	nodes := make([]category.Node, 0, len(categories))
+	for _, c := range categories {
+		nodes = append(nodes, category.Node{
+			ID:       c.ID,
+			Name:     c.Name,
+			Slug:     c.Slug,
+			Children: toCategoryNodes(c.Children),
+		})
+	}
+	return nodes
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/content"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) ReloadContentPolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := h.service.ReloadContentPolicy(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrNotAdmin):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, model.ErrNoContentPolicy):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, context.Canceled):
+			http.Error(w, "Request Timeout", http.StatusRequestTimeout)
+		default:
+			http.Error(w, "Failed to reload content policy: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(content.ReloadResponse{Rules: rules})
+}
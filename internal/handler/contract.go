@@ -2,17 +2,66 @@ package handler
 
 import (
 	"context"
+	"io"
 	"net/url"
+	"time"
 
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
 	"github.com/AugustSerenity/marketplace/internal/model"
 )
 
 type Service interface {
 	RegisterUser(ctx context.Context, req *auth.RegistrationRequest) (*auth.RegistrationResponse, error)
-	LoginUser(ctx context.Context, login, password string) (string, error)
+	LoginUser(ctx context.Context, login, password string) (*auth.LoginResponse, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*auth.LoginResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+	PublicKeys() jwtkeys.JWKS
+	SetUserRole(ctx context.Context, targetID int64, role string, userID int64) (*model.User, error)
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeAllTokens(ctx context.Context, userID int64) error
+	RevokeUserTokens(ctx context.Context, targetID, userID int64) error
+	ChangePassword(ctx context.Context, currentPassword, newPassword string, userID int64) error
+	RequestPasswordReset(ctx context.Context, login string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	DeleteAccount(ctx context.Context, password string, userID int64) error
+	SetEmail(ctx context.Context, email string, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
 	CreateAd(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error)
-	GetAds(ctx context.Context, req *ad.ListRequest, userID int64) ([]*model.AdWithAuthor, error)
+	GetVisibleAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error)
+	UpdateAd(ctx context.Context, id int64, req ad.UpdateRequest, userID int64) (*model.Ad, error)
+	DeleteAd(ctx context.Context, id int64, userID int64) error
+	ArchiveAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error)
+	ApproveAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error)
+	RejectAd(ctx context.Context, id int64, reason string, userID int64) (*model.AdWithAuthor, error)
+	GetModerationQueue(ctx context.Context, page, pageSize int, userID int64) (*model.AdList, error)
+	ReportAd(ctx context.Context, adID int64, reason, comment string, userID int64) (*model.Report, error)
+	GetAdReports(ctx context.Context, adID, userID int64) ([]model.Report, error)
+	DismissReports(ctx context.Context, adID, userID int64) error
+	GetReportedAds(ctx context.Context, page, pageSize int, userID int64) (*model.ReportedAdList, error)
+	ReplaceAdImages(ctx context.Context, adID int64, urls []string, userID int64) ([]model.AdImage, error)
+	DeleteAdImage(ctx context.Context, adID, imageID int64, userID int64) ([]model.AdImage, error)
+	AddFavorite(ctx context.Context, adID, userID int64) error
+	RemoveFavorite(ctx context.Context, adID, userID int64) error
+	GetFavorites(ctx context.Context, page, pageSize int, currency string, userID int64) (*model.AdList, error)
+	StartThread(ctx context.Context, adID int64, message string, userID int64) (*model.Thread, bool, error)
+	GetThreads(ctx context.Context, page, pageSize int, userID int64) (*model.ThreadList, error)
+	GetThreadMessages(ctx context.Context, threadID, afterID int64, limit int, userID int64) ([]model.Message, error)
+	PostMessage(ctx context.Context, threadID int64, message string, userID int64) (*model.Message, error)
+	MarkThreadRead(ctx context.Context, threadID, userID int64) error
+	CreateReview(ctx context.Context, adID int64, rating int, text string, userID int64) (*model.Review, error)
+	GetProfile(ctx context.Context, login string) (*model.Profile, error)
+	GetMe(ctx context.Context, userID int64) (*model.Profile, error)
+	UpdateProfile(ctx context.Context, req user.UpdateProfileRequest, userID int64) (*model.Profile, error)
+	GetUserAds(ctx context.Context, login string, req *ad.ListRequest, userID int64) (*model.AdList, error)
+	GetSellerReviews(ctx context.Context, login string, page, pageSize int) (*model.ReviewList, error)
+	GetAds(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error)
 	ParseListRequest(q url.Values) (ad.ListRequest, error)
+	GetCategoryTree(ctx context.Context) ([]*model.Category, error)
+	UploadImage(ctx context.Context, r io.Reader, userID int64) (*model.Image, error)
+	GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, currency, rate string, userID int64) (*model.ExchangeRate, error)
+	ReloadContentPolicy(ctx context.Context, userID int64) (int, error)
 }
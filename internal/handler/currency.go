@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/rate"
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
+func (h *Handler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rates, err := h.service.GetExchangeRates(r.Context())
+	if err != nil {
+		http.Error(w, "Failed to fetch exchange rates", http.StatusInternalServerError)
+		return
+	}
+
+	resp := make([]rate.Response, 0, len(rates))
+	for _, er := range rates {
+		resp = append(resp, toRateResponse(&er))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) UpdateExchangeRate(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPut {
+		http.Error(w, "Only PUT method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req rate.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.service.UpdateExchangeRate(r.Context(), r.PathValue("currency"), req.Rate.String(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrNotAdmin):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, model.ErrBaseCurrencyRate), errors.Is(err, money.ErrInvalidCurrency),
+			errors.Is(err, money.ErrInvalidRate):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to update exchange rate", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toRateResponse(updated))
+}
+
+func toRateResponse(r *model.ExchangeRate) rate.Response {
+	return rate.Response{
+		Currency:  r.Currency,
+		Rate:      r.Rate,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
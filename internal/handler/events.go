@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/events"
+)
+
+const (
+	defaultHeartbeat = 25 * time.Second
+	reconnectDelayMS = 3000
+)
+
+func WithEvents(hub *events.Hub, heartbeat time.Duration) Option {
+	return func(h *Handler) {
+		if heartbeat <= 0 {
+			heartbeat = defaultHeartbeat
+		}
+		h.events = hub
+		h.heartbeat = heartbeat
+	}
+}
+
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if h.events == nil {
+		http.Error(w, "Event stream is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	sub, missed := h.events.Subscribe(userID, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", reconnectDelayMS)
+	for _, event := range missed {
+		writeEvent(w, event)
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	var expired <-chan time.Time
+	if expiresAt, ok := r.Context().Value("tokenExpiresAt").(time.Time); ok && !expiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-expired:
+			return
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			writeEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event events.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
+
+func parseLastEventID(r *http.Request) (uint64, error) {
+	val := r.Header.Get("Last-Event-ID")
+	if val == "" {
+		val = r.URL.Query().Get("last_event_id")
+	}
+	if val == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid Last-Event-ID value")
+	}
+	return id, nil
+}
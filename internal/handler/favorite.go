@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
+const (
+	defaultFavoritesPageSize = 20
+	maxFavoritesPageSize     = 100
+)
+
+func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Only PUT method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.changeFavorite(w, r, h.service.AddFavorite)
+}
+
+func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.changeFavorite(w, r, h.service.RemoveFavorite)
+}
+
+func (h *Handler) GetFavorites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	q := r.URL.Query()
+	page, pageSize, err := parsePagination(q, defaultFavoritesPageSize, maxFavoritesPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var currency string
+	if val := q.Get("currency"); val != "" {
+		currency, err = money.ParseCurrency(val)
+		if err != nil {
+			http.Error(w, "invalid currency value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	list, err := h.service.GetFavorites(r.Context(), page, pageSize, currency, userID)
+	if err != nil {
+		writeAdError(w, err, "Failed to fetch favorites")
+		return
+	}
+
+	if currency == "" {
+		currency = money.BaseCurrency
+	}
+	resp := ad.FavoritesPage{
+		Items:      make([]ad.ListResponse, 0, len(list.Ads)),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      list.Total,
+		TotalPages: totalPages(list.Total, pageSize),
+		Currency:   currency,
+	}
+	for _, adItem := range list.Ads {
+		resp.Items = append(resp.Items, toListResponse(adItem, userID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) changeFavorite(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, adID, userID int64) error) {
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := change(r.Context(), id, userID); err != nil {
+		writeAdError(w, err, "Failed to update favorites")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
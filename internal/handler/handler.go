@@ -5,35 +5,104 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/AugustSerenity/marketplace/internal/events"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
 	"github.com/AugustSerenity/marketplace/internal/middleware"
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/money"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
 	"github.com/go-playground/validator/v10"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type Handler struct {
 	service  Service
-	secret   string
+	keys     jwt.Keyfunc
 	validate *validator.Validate
+	revoked  revocation.Store
+
+	events    *events.Hub
+	heartbeat time.Duration
+}
+
+type Option func(*Handler)
+
+func WithRevocation(store revocation.Store) Option {
+	return func(h *Handler) {
+		h.revoked = store
+	}
 }
 
-func New(s Service, secret string) *Handler {
-	return &Handler{
+func New(s Service, keys jwt.Keyfunc, opts ...Option) *Handler {
+	h := &Handler{
 		service:  s,
-		secret:   secret,
+		keys:     keys,
 		validate: validator.New(),
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *Handler) Route() http.Handler {
 	router := http.NewServeMux()
 
+	router.HandleFunc("GET /.well-known/jwks.json", h.GetJWKS)
 	router.HandleFunc("POST /auth-register", h.UserRegistration)
 	router.HandleFunc("POST /auth-login", h.LoginUser)
-	router.Handle("POST /create-ads", middleware.AuthMiddleware(h.secret)(http.HandlerFunc(h.CreateAd)))
-	router.Handle("GET /watch-ads", middleware.OptionalAuthMiddleware(h.secret)(http.HandlerFunc(h.GetAds)))
+	router.HandleFunc("POST /auth-refresh", h.RefreshTokens)
+	router.HandleFunc("POST /auth-logout", h.Logout)
+	router.HandleFunc("POST /auth-password-reset", h.RequestPasswordReset)
+	router.HandleFunc("POST /auth-password-reset-confirm", h.ResetPassword)
+	router.HandleFunc("POST /auth-verify-email", h.VerifyEmail)
+	router.Handle("POST /auth-revoke", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.RevokeToken)))
+	router.Handle("POST /auth-revoke-all", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.RevokeAllTokens)))
+	router.Handle("POST /users/{id}/revoke-tokens", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleAdmin)(http.HandlerFunc(h.RevokeUserTokens))))
+	router.Handle("PUT /users/{id}/role", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleAdmin)(http.HandlerFunc(h.UpdateUserRole))))
+	router.Handle("POST /create-ads", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.CreateAd)))
+	router.Handle("GET /watch-ads", middleware.OptionalAuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetAds)))
+	router.Handle("GET /ads/{id}", middleware.OptionalAuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetAd)))
+	router.Handle("PATCH /ads/{id}", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.UpdateAd)))
+	router.Handle("DELETE /ads/{id}", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.DeleteAd)))
+	router.Handle("POST /ads/{id}/archive", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.ArchiveAd)))
+	router.Handle("POST /ads/{id}/approve", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleModerator, model.RoleAdmin)(http.HandlerFunc(h.ApproveAd))))
+	router.Handle("POST /ads/{id}/reject", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleModerator, model.RoleAdmin)(http.HandlerFunc(h.RejectAd))))
+	router.Handle("GET /moderation/ads", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleModerator, model.RoleAdmin)(http.HandlerFunc(h.GetModerationQueue))))
+	router.Handle("POST /ads/{id}/reports", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.ReportAd)))
+	router.Handle("GET /ads/{id}/reports", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleModerator, model.RoleAdmin)(http.HandlerFunc(h.GetAdReports))))
+	router.Handle("POST /ads/{id}/reports/dismiss", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleModerator, model.RoleAdmin)(http.HandlerFunc(h.DismissReports))))
+	router.Handle("GET /moderation/reports", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleModerator, model.RoleAdmin)(http.HandlerFunc(h.GetReportedAds))))
+	router.Handle("PUT /ads/{id}/favorite", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.AddFavorite)))
+	router.Handle("DELETE /ads/{id}/favorite", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.RemoveFavorite)))
+	router.Handle("GET /me/favorites", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetFavorites)))
+	router.Handle("POST /ads/{id}/threads", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.StartThread)))
+	router.Handle("POST /ads/{id}/reviews", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.CreateReview)))
+	router.HandleFunc("GET /users/{login}", h.GetProfile)
+	router.HandleFunc("GET /users/{login}/reviews", h.GetSellerReviews)
+	router.Handle("GET /users/{login}/ads", middleware.OptionalAuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetUserAds)))
+	router.Handle("GET /me", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetMe)))
+	router.Handle("PATCH /me", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.UpdateMe)))
+	router.Handle("DELETE /me", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.DeleteMe)))
+	router.Handle("POST /me/password", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.ChangePassword)))
+	router.Handle("POST /me/email", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.SetEmail)))
+	router.Handle("GET /events", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.StreamEvents)))
+	router.Handle("GET /threads", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetThreads)))
+	router.Handle("GET /threads/{id}/messages", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.GetThreadMessages)))
+	router.Handle("POST /threads/{id}/messages", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.PostMessage)))
+	router.Handle("POST /threads/{id}/read", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.MarkThreadRead)))
+	router.Handle("PUT /ads/{id}/images", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.ReplaceAdImages)))
+	router.Handle("DELETE /ads/{id}/images/{imageID}", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.DeleteAdImage)))
+	router.HandleFunc("GET /categories", h.GetCategories)
+	router.Handle("POST /images", middleware.AuthMiddleware(h.keys, h.revoked)(http.HandlerFunc(h.UploadImage)))
+	router.HandleFunc("GET /exchange-rates", h.GetExchangeRates)
+	router.Handle("PUT /exchange-rates/{currency}", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleAdmin)(http.HandlerFunc(h.UpdateExchangeRate))))
+	router.Handle("POST /content-policy/reload", middleware.AuthMiddleware(h.keys, h.revoked)(middleware.RequireRole(model.RoleAdmin)(http.HandlerFunc(h.ReloadContentPolicy))))
 
 	return router
 }
@@ -68,6 +137,11 @@ func (h *Handler) UserRegistration(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "User with this login already exists", http.StatusConflict)
 			return
 		}
+		var fieldErr *model.FieldError
+		if errors.As(err, &fieldErr) || errors.Is(err, model.ErrEmailTaken) || errors.Is(err, model.ErrEmailVerificationDisabled) {
+			writeAccountError(w, err, "Failed to register user")
+			return
+		}
 		http.Error(w, "Failed to register user", http.StatusInternalServerError)
 		return
 	}
@@ -101,7 +175,7 @@ func (h *Handler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.service.LoginUser(r.Context(), req.Login, req.Password)
+	resp, err := h.service.LoginUser(r.Context(), req.Login, req.Password)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			http.Error(w, "Request Timeout", http.StatusRequestTimeout)
@@ -111,7 +185,6 @@ func (h *Handler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := auth.LoginResponse{Token: token}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
@@ -138,7 +211,7 @@ func (h *Handler) CreateAd(w http.ResponseWriter, r *http.Request) {
 
 	var req ad.CreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeDecodeError(w, err)
 		return
 	}
 
@@ -149,24 +222,117 @@ func (h *Handler) CreateAd(w http.ResponseWriter, r *http.Request) {
 
 	createdAd, err := h.service.CreateAd(r.Context(), req, userID)
 	if err != nil {
-		http.Error(w, "Failed to create ad: "+err.Error(), http.StatusBadRequest)
+		writeAdError(w, err, "Failed to create ad")
 		return
 	}
 
-	resp := ad.Response{
-		ID:          createdAd.ID,
-		Title:       createdAd.Title,
-		Description: createdAd.Description,
-		ImageURL:    createdAd.ImageURL,
-		Price:       createdAd.Price,
-		AuthorID:    createdAd.AuthorID,
-	}
+	resp := toAdResponse(createdAd, userID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(resp)
 }
 
+func (h *Handler) GetAd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := userIDFromContext(r)
+
+	adItem, err := h.service.GetVisibleAd(r.Context(), id, userID)
+	if err != nil {
+		writeAdError(w, err, "Failed to fetch ad")
+		return
+	}
+
+	resp := toAdWithAuthorResponse(adItem, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) UpdateAd(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Only PATCH method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req ad.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updatedAd, err := h.service.UpdateAd(r.Context(), id, req, userID)
+	if err != nil {
+		writeAdError(w, err, "Failed to update ad")
+		return
+	}
+
+	resp := toAdResponse(updatedAd, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) DeleteAd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteAd(r.Context(), id, userID); err != nil {
+		writeAdError(w, err, "Failed to delete ad")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) GetAds(w http.ResponseWriter, r *http.Request) {
 
 	if r.Method != http.MethodGet {
@@ -189,25 +355,191 @@ func (h *Handler) GetAds(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	ads, err := h.service.GetAds(r.Context(), &req, userID)
+	list, err := h.service.GetAds(r.Context(), &req, userID)
 	if err != nil {
+		if errors.Is(err, model.ErrUnsupportedCurrency) || errors.Is(err, model.ErrCursorMismatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Failed to fetch ads", http.StatusInternalServerError)
 		return
 	}
 
-	var resp []ad.ListResponse
-	for _, adItem := range ads {
-		resp = append(resp, ad.ListResponse{
-			ID:          adItem.ID,
-			Title:       adItem.Title,
-			Description: adItem.Description,
-			ImageURL:    adItem.ImageURL,
-			Price:       adItem.Price,
-			AuthorLogin: adItem.AuthorLogin,
-			IsOwner:     userID == adItem.AuthorID,
-		})
+	writeAdList(w, req, list, userID)
+}
+
+func writeAdList(w http.ResponseWriter, req ad.ListRequest, list *model.AdList, userID int64) {
+	resp := ad.ListPage{
+		Items:      make([]ad.ListResponse, 0, len(list.Ads)),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		Total:      list.Total,
+		TotalPages: totalPages(list.Total, req.PageSize),
+		SortBy:     req.SortBy,
+		SortOrder:  req.SortOrder,
+		Currency:   req.Currency,
+		NextCursor: list.NextCursor,
+	}
+	for _, adItem := range list.Ads {
+		resp.Items = append(resp.Items, toListResponse(adItem, userID))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
+
+func userIDFromContext(r *http.Request) (int64, bool) {
+	userID, ok := r.Context().Value("userID").(int64)
+	return userID, ok
+}
+
+func parseAdID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid ad id")
+	}
+	return id, nil
+}
+
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, money.ErrInvalid) || errors.Is(err, money.ErrNegative) ||
+		errors.Is(err, money.ErrScale) || errors.Is(err, money.ErrTooLarge) {
+		http.Error(w, "Validation failed: price: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "Invalid JSON", http.StatusBadRequest)
+}
+
+func writeAdError(w http.ResponseWriter, err error, fallback string) {
+	var fieldErr *model.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ad.FieldError{
+			Error:  fieldErr.Error(),
+			Field:  fieldErr.Field,
+			Reason: fieldErr.Reason,
+			Rule:   fieldErr.Rule,
+		})
+	case errors.Is(err, model.ErrAdNotFound), errors.Is(err, model.ErrAdImageNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, model.ErrNotAdAuthor), errors.Is(err, model.ErrNotModerator), errors.Is(err, model.ErrEmailNotVerified):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, model.ErrInvalidAdTransition), errors.Is(err, model.ErrAdStatusChanged):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, model.ErrEmptyUpdate), errors.Is(err, model.ErrCategoryNotFound),
+		errors.Is(err, model.ErrImageNotUploaded), errors.Is(err, model.ErrTooManyImages),
+		errors.Is(err, model.ErrNoImages), errors.Is(err, model.ErrDuplicateImage),
+		errors.Is(err, model.ErrUnsupportedCurrency), errors.Is(err, model.ErrCursorMismatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, context.Canceled):
+		http.Error(w, "Request Timeout", http.StatusRequestTimeout)
+	default:
+		http.Error(w, fallback+": "+err.Error(), http.StatusBadRequest)
+	}
+}
+
+func totalPages(total int64, pageSize int) int {
+	if pageSize <= 0 {
+		return 0
+	}
+	return int((total + int64(pageSize) - 1) / int64(pageSize))
+}
+
+func toImageVariants(v model.ImageVariants) *ad.ImageVariants {
+	if v.Thumbnail == "" && v.Medium == "" {
+		return nil
+	}
+	return &ad.ImageVariants{
+		Thumbnail: v.Thumbnail,
+		Medium:    v.Medium,
+	}
+}
+
+func toAdImages(images []model.AdImage) []ad.Image {
+	if len(images) == 0 {
+		return nil
+	}
+
+	resp := make([]ad.Image, 0, len(images))
+	for _, img := range images {
+		resp = append(resp, ad.Image{
+			ID:            img.ID,
+			URL:           img.URL,
+			Position:      img.Position,
+			ImageVariants: toImageVariants(img.Variants),
+		})
+	}
+	return resp
+}
+
+func toAdResponse(a *model.Ad, userID int64) ad.Response {
+	return ad.Response{
+		ID:              a.ID,
+		Title:           a.Title,
+		Description:     a.Description,
+		ImageURL:        a.ImageURL,
+		Price:           a.Price,
+		Currency:        a.Currency,
+		ImageVariants:   toImageVariants(a.ImageVariants),
+		Images:          toAdImages(a.Images),
+		AuthorID:        a.AuthorID,
+		CategoryID:      a.CategoryID,
+		Status:          a.Status,
+		RejectionReason: a.RejectionReason,
+		PolicyMatches:   policyMatches(a.Status, a.PolicyMatches),
+		IsOwner:         userID == a.AuthorID,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+	}
+}
+
+func toListResponse(a *model.AdWithAuthor, userID int64) ad.ListResponse {
+	return ad.ListResponse{
+		ID:            a.ID,
+		Title:         a.Title,
+		Description:   a.Description,
+		ImageURL:      a.ImageURL,
+		Price:         a.Price,
+		Currency:      a.Currency,
+		DisplayPrice:  a.DisplayPrice,
+		CategoryID:    a.CategoryID,
+		ImageVariants: toImageVariants(a.ImageVariants),
+		Images:        toAdImages(a.Images),
+		AuthorLogin:   a.AuthorLogin,
+		AuthorRating:  a.AuthorRating,
+		IsOwner:       userID == a.AuthorID,
+		IsFavorite:    a.IsFavorite,
+	}
+}
+
+func policyMatches(status string, rules []string) []string {
+	if status == model.AdStatusPublished {
+		return nil
+	}
+	return rules
+}
+
+func toAdWithAuthorResponse(a *model.AdWithAuthor, userID int64) ad.Response {
+	return ad.Response{
+		ID:              a.ID,
+		Title:           a.Title,
+		Description:     a.Description,
+		ImageURL:        a.ImageURL,
+		Price:           a.Price,
+		Currency:        a.Currency,
+		AuthorID:        a.AuthorID,
+		ImageVariants:   toImageVariants(a.ImageVariants),
+		Images:          toAdImages(a.Images),
+		CategoryID:      a.CategoryID,
+		AuthorLogin:     a.AuthorLogin,
+		Status:          a.Status,
+		RejectionReason: a.RejectionReason,
+		PolicyMatches:   policyMatches(a.Status, a.PolicyMatches),
+		Hidden:          a.HiddenAt != nil,
+		IsOwner:         userID == a.AuthorID,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+	}
+}
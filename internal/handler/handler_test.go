@@ -1,10 +1,14 @@
 package handler_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,35 +16,199 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AugustSerenity/marketplace/internal/events"
 	"github.com/AugustSerenity/marketplace/internal/handler"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/category"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
 	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/money"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func mustAmount(s string) money.Amount {
+	a, err := money.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
 type mockService struct {
 	RegisterUserFunc     func(ctx context.Context, req *auth.RegistrationRequest) (*auth.RegistrationResponse, error)
-	LoginUserFunc        func(ctx context.Context, login, password string) (string, error)
+	LoginUserFunc        func(ctx context.Context, login, password string) (*auth.LoginResponse, error)
+	RefreshTokensFunc    func(ctx context.Context, refreshToken string) (*auth.LoginResponse, error)
+	LogoutFunc           func(ctx context.Context, refreshToken string) error
+	PublicKeysFunc       func() jwtkeys.JWKS
+	SetUserRoleFunc      func(ctx context.Context, targetID int64, role string, userID int64) (*model.User, error)
+	RevokeTokenFunc      func(ctx context.Context, tokenID string, expiresAt time.Time) error
+	RevokeAllTokensFunc  func(ctx context.Context, userID int64) error
+	RevokeUserTokensFunc func(ctx context.Context, targetID, userID int64) error
+	ChangePasswordFunc   func(ctx context.Context, currentPassword, newPassword string, userID int64) error
+	RequestResetFunc     func(ctx context.Context, login string) error
+	ResetPasswordFunc    func(ctx context.Context, token, newPassword string) error
+	DeleteAccountFunc    func(ctx context.Context, password string, userID int64) error
+	SetEmailFunc         func(ctx context.Context, email string, userID int64) error
+	VerifyEmailFunc      func(ctx context.Context, token string) error
 	CreateAdFunc         func(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error)
-	GetAdsFunc           func(ctx context.Context, req *ad.ListRequest, userID int64) ([]*model.AdWithAuthor, error)
+	GetVisibleAdFunc     func(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error)
+	ArchiveAdFunc        func(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error)
+	ApproveAdFunc        func(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error)
+	RejectAdFunc         func(ctx context.Context, id int64, reason string, userID int64) (*model.AdWithAuthor, error)
+	ModerationQueueFunc  func(ctx context.Context, page, pageSize int, userID int64) (*model.AdList, error)
+	ReportAdFunc         func(ctx context.Context, adID int64, reason, comment string, userID int64) (*model.Report, error)
+	GetAdReportsFunc     func(ctx context.Context, adID, userID int64) ([]model.Report, error)
+	DismissReportsFunc   func(ctx context.Context, adID, userID int64) error
+	GetReportedAdsFunc   func(ctx context.Context, page, pageSize int, userID int64) (*model.ReportedAdList, error)
+	UpdateAdFunc         func(ctx context.Context, id int64, req ad.UpdateRequest, userID int64) (*model.Ad, error)
+	DeleteAdFunc         func(ctx context.Context, id int64, userID int64) error
+	ReplaceAdImagesFunc  func(ctx context.Context, adID int64, urls []string, userID int64) ([]model.AdImage, error)
+	DeleteAdImageFunc    func(ctx context.Context, adID, imageID int64, userID int64) ([]model.AdImage, error)
+	GetAdsFunc           func(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error)
 	ParseListRequestFunc func(q url.Values) (ad.ListRequest, error)
+	GetCategoryTreeFunc  func(ctx context.Context) ([]*model.Category, error)
+	UploadImageFunc      func(ctx context.Context, r io.Reader, userID int64) (*model.Image, error)
+	GetRatesFunc         func(ctx context.Context) ([]model.ExchangeRate, error)
+	UpdateRateFunc       func(ctx context.Context, currency, rate string, userID int64) (*model.ExchangeRate, error)
+	ReloadPolicyFunc     func(ctx context.Context, userID int64) (int, error)
+	AddFavoriteFunc      func(ctx context.Context, adID, userID int64) error
+	RemoveFavoriteFunc   func(ctx context.Context, adID, userID int64) error
+	GetFavoritesFunc     func(ctx context.Context, page, pageSize int, currency string, userID int64) (*model.AdList, error)
+	CreateReviewFunc     func(ctx context.Context, adID int64, rating int, text string, userID int64) (*model.Review, error)
+	GetProfileFunc       func(ctx context.Context, login string) (*model.Profile, error)
+	GetMeFunc            func(ctx context.Context, userID int64) (*model.Profile, error)
+	UpdateProfileFunc    func(ctx context.Context, req user.UpdateProfileRequest, userID int64) (*model.Profile, error)
+	GetUserAdsFunc       func(ctx context.Context, login string, req *ad.ListRequest, userID int64) (*model.AdList, error)
+	GetReviewsFunc       func(ctx context.Context, login string, page, pageSize int) (*model.ReviewList, error)
+	StartThreadFunc      func(ctx context.Context, adID int64, message string, userID int64) (*model.Thread, bool, error)
+	GetThreadsFunc       func(ctx context.Context, page, pageSize int, userID int64) (*model.ThreadList, error)
+	GetMessagesFunc      func(ctx context.Context, threadID, afterID int64, limit int, userID int64) ([]model.Message, error)
+	PostMessageFunc      func(ctx context.Context, threadID int64, message string, userID int64) (*model.Message, error)
+	MarkThreadReadFunc   func(ctx context.Context, threadID, userID int64) error
 }
 
 func (m *mockService) RegisterUser(ctx context.Context, req *auth.RegistrationRequest) (*auth.RegistrationResponse, error) {
 	return m.RegisterUserFunc(ctx, req)
 }
 
-func (m *mockService) LoginUser(ctx context.Context, login, password string) (string, error) {
+func (m *mockService) LoginUser(ctx context.Context, login, password string) (*auth.LoginResponse, error) {
 	return m.LoginUserFunc(ctx, login, password)
 }
 
+func (m *mockService) RefreshTokens(ctx context.Context, refreshToken string) (*auth.LoginResponse, error) {
+	return m.RefreshTokensFunc(ctx, refreshToken)
+}
+
+func (m *mockService) Logout(ctx context.Context, refreshToken string) error {
+	return m.LogoutFunc(ctx, refreshToken)
+}
+
+func (m *mockService) PublicKeys() jwtkeys.JWKS {
+	return m.PublicKeysFunc()
+}
+
+func (m *mockService) SetUserRole(ctx context.Context, targetID int64, role string, userID int64) (*model.User, error) {
+	return m.SetUserRoleFunc(ctx, targetID, role, userID)
+}
+
+func (m *mockService) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	return m.RevokeTokenFunc(ctx, tokenID, expiresAt)
+}
+
+func (m *mockService) RevokeAllTokens(ctx context.Context, userID int64) error {
+	return m.RevokeAllTokensFunc(ctx, userID)
+}
+
+func (m *mockService) RevokeUserTokens(ctx context.Context, targetID, userID int64) error {
+	return m.RevokeUserTokensFunc(ctx, targetID, userID)
+}
+
+func (m *mockService) ChangePassword(ctx context.Context, currentPassword, newPassword string, userID int64) error {
+	return m.ChangePasswordFunc(ctx, currentPassword, newPassword, userID)
+}
+
+func (m *mockService) RequestPasswordReset(ctx context.Context, login string) error {
+	return m.RequestResetFunc(ctx, login)
+}
+
+func (m *mockService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	return m.ResetPasswordFunc(ctx, token, newPassword)
+}
+
+func (m *mockService) DeleteAccount(ctx context.Context, password string, userID int64) error {
+	return m.DeleteAccountFunc(ctx, password, userID)
+}
+
+func (m *mockService) SetEmail(ctx context.Context, email string, userID int64) error {
+	return m.SetEmailFunc(ctx, email, userID)
+}
+
+func (m *mockService) VerifyEmail(ctx context.Context, token string) error {
+	return m.VerifyEmailFunc(ctx, token)
+}
+
 func (m *mockService) CreateAd(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error) {
 	return m.CreateAdFunc(ctx, req, userID)
 }
 
-func (m *mockService) GetAds(ctx context.Context, req *ad.ListRequest, userID int64) ([]*model.AdWithAuthor, error) {
+func (m *mockService) GetVisibleAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+	return m.GetVisibleAdFunc(ctx, id, userID)
+}
+
+func (m *mockService) ArchiveAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+	return m.ArchiveAdFunc(ctx, id, userID)
+}
+
+func (m *mockService) ApproveAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+	return m.ApproveAdFunc(ctx, id, userID)
+}
+
+func (m *mockService) RejectAd(ctx context.Context, id int64, reason string, userID int64) (*model.AdWithAuthor, error) {
+	return m.RejectAdFunc(ctx, id, reason, userID)
+}
+
+func (m *mockService) GetModerationQueue(ctx context.Context, page, pageSize int, userID int64) (*model.AdList, error) {
+	return m.ModerationQueueFunc(ctx, page, pageSize, userID)
+}
+
+func (m *mockService) UpdateAd(ctx context.Context, id int64, req ad.UpdateRequest, userID int64) (*model.Ad, error) {
+	return m.UpdateAdFunc(ctx, id, req, userID)
+}
+
+func (m *mockService) DeleteAd(ctx context.Context, id int64, userID int64) error {
+	return m.DeleteAdFunc(ctx, id, userID)
+}
+
+func (m *mockService) ReportAd(ctx context.Context, adID int64, reason, comment string, userID int64) (*model.Report, error) {
+	return m.ReportAdFunc(ctx, adID, reason, comment, userID)
+}
+
+func (m *mockService) GetAdReports(ctx context.Context, adID, userID int64) ([]model.Report, error) {
+	return m.GetAdReportsFunc(ctx, adID, userID)
+}
+
+func (m *mockService) DismissReports(ctx context.Context, adID, userID int64) error {
+	return m.DismissReportsFunc(ctx, adID, userID)
+}
+
+func (m *mockService) GetReportedAds(ctx context.Context, page, pageSize int, userID int64) (*model.ReportedAdList, error) {
+	return m.GetReportedAdsFunc(ctx, page, pageSize, userID)
+}
+
+func (m *mockService) ReplaceAdImages(ctx context.Context, adID int64, urls []string, userID int64) ([]model.AdImage, error) {
+	return m.ReplaceAdImagesFunc(ctx, adID, urls, userID)
+}
+
+func (m *mockService) DeleteAdImage(ctx context.Context, adID, imageID int64, userID int64) ([]model.AdImage, error) {
+	return m.DeleteAdImageFunc(ctx, adID, imageID, userID)
+}
+
+func (m *mockService) GetAds(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error) {
 	return m.GetAdsFunc(ctx, req, userID)
 }
 
@@ -48,6 +216,82 @@ func (m *mockService) ParseListRequest(q url.Values) (ad.ListRequest, error) {
 	return m.ParseListRequestFunc(q)
 }
 
+func (m *mockService) GetCategoryTree(ctx context.Context) ([]*model.Category, error) {
+	return m.GetCategoryTreeFunc(ctx)
+}
+
+func (m *mockService) UploadImage(ctx context.Context, r io.Reader, userID int64) (*model.Image, error) {
+	return m.UploadImageFunc(ctx, r, userID)
+}
+
+func (m *mockService) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	return m.GetRatesFunc(ctx)
+}
+
+func (m *mockService) UpdateExchangeRate(ctx context.Context, currency, rate string, userID int64) (*model.ExchangeRate, error) {
+	return m.UpdateRateFunc(ctx, currency, rate, userID)
+}
+
+func (m *mockService) AddFavorite(ctx context.Context, adID, userID int64) error {
+	return m.AddFavoriteFunc(ctx, adID, userID)
+}
+
+func (m *mockService) RemoveFavorite(ctx context.Context, adID, userID int64) error {
+	return m.RemoveFavoriteFunc(ctx, adID, userID)
+}
+
+func (m *mockService) GetFavorites(ctx context.Context, page, pageSize int, currency string, userID int64) (*model.AdList, error) {
+	return m.GetFavoritesFunc(ctx, page, pageSize, currency, userID)
+}
+
+func (m *mockService) CreateReview(ctx context.Context, adID int64, rating int, text string, userID int64) (*model.Review, error) {
+	return m.CreateReviewFunc(ctx, adID, rating, text, userID)
+}
+
+func (m *mockService) GetProfile(ctx context.Context, login string) (*model.Profile, error) {
+	return m.GetProfileFunc(ctx, login)
+}
+
+func (m *mockService) GetMe(ctx context.Context, userID int64) (*model.Profile, error) {
+	return m.GetMeFunc(ctx, userID)
+}
+
+func (m *mockService) UpdateProfile(ctx context.Context, req user.UpdateProfileRequest, userID int64) (*model.Profile, error) {
+	return m.UpdateProfileFunc(ctx, req, userID)
+}
+
+func (m *mockService) GetUserAds(ctx context.Context, login string, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+	return m.GetUserAdsFunc(ctx, login, req, userID)
+}
+
+func (m *mockService) GetSellerReviews(ctx context.Context, login string, page, pageSize int) (*model.ReviewList, error) {
+	return m.GetReviewsFunc(ctx, login, page, pageSize)
+}
+
+func (m *mockService) StartThread(ctx context.Context, adID int64, message string, userID int64) (*model.Thread, bool, error) {
+	return m.StartThreadFunc(ctx, adID, message, userID)
+}
+
+func (m *mockService) GetThreads(ctx context.Context, page, pageSize int, userID int64) (*model.ThreadList, error) {
+	return m.GetThreadsFunc(ctx, page, pageSize, userID)
+}
+
+func (m *mockService) GetThreadMessages(ctx context.Context, threadID, afterID int64, limit int, userID int64) ([]model.Message, error) {
+	return m.GetMessagesFunc(ctx, threadID, afterID, limit, userID)
+}
+
+func (m *mockService) PostMessage(ctx context.Context, threadID int64, message string, userID int64) (*model.Message, error) {
+	return m.PostMessageFunc(ctx, threadID, message, userID)
+}
+
+func (m *mockService) MarkThreadRead(ctx context.Context, threadID, userID int64) error {
+	return m.MarkThreadReadFunc(ctx, threadID, userID)
+}
+
+func (m *mockService) ReloadContentPolicy(ctx context.Context, userID int64) (int, error) {
+	return m.ReloadPolicyFunc(ctx, userID)
+}
+
 func TestHandler_LoginUser(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -94,12 +338,15 @@ func TestHandler_LoginUser(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockSvc := &mockService{
-				LoginUserFunc: func(ctx context.Context, login, password string) (string, error) {
-					return tt.mockReturn, tt.mockError
+				LoginUserFunc: func(ctx context.Context, login, password string) (*auth.LoginResponse, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return &auth.LoginResponse{Token: tt.mockReturn, RefreshToken: "refresh123"}, nil
 				},
 			}
 
-			h := handler.New(mockSvc, "secret")
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
 
 			req := httptest.NewRequest(http.MethodPost, "/auth-login", strings.NewReader(tt.requestBody))
 			if tt.contentType != "" {
@@ -116,6 +363,263 @@ func TestHandler_LoginUser(t *testing.T) {
 				err := json.NewDecoder(w.Body).Decode(&resp)
 				require.NoError(t, err)
 				assert.Equal(t, tt.mockReturn, resp.Token)
+				assert.Equal(t, "refresh123", resp.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestHandler_RefreshTokens(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestBody string
+		mockError   error
+		wantStatus  int
+	}{
+		{
+			name:        "successful refresh",
+			requestBody: `{"refresh_token":"refresh123"}`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "missing token",
+			requestBody: `{}`,
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "unknown token",
+			requestBody: `{"refresh_token":"nope"}`,
+			mockError:   model.ErrInvalidRefreshToken,
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "reused token",
+			requestBody: `{"refresh_token":"refresh123"}`,
+			mockError:   model.ErrRefreshTokenReused,
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				RefreshTokensFunc: func(ctx context.Context, refreshToken string) (*auth.LoginResponse, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return &auth.LoginResponse{Token: "access", RefreshToken: refreshToken + "-next"}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodPost, "/auth-refresh", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			h.RefreshTokens(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusOK {
+				var resp auth.LoginResponse
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, "refresh123-next", resp.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	var revoked string
+	mockSvc := &mockService{
+		LogoutFunc: func(ctx context.Context, refreshToken string) error {
+			revoked = refreshToken
+			return nil
+		},
+	}
+
+	h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth-logout", strings.NewReader(`{"refresh_token":"refresh123"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	h.Logout(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "refresh123", revoked)
+}
+
+func TestHandler_GetJWKS(t *testing.T) {
+	ring, err := jwtkeys.NewKeyring(t.TempDir(), jwtkeys.AlgEdDSA)
+	require.NoError(t, err)
+
+	mockSvc := &mockService{
+		PublicKeysFunc: ring.JWKS,
+	}
+
+	h := handler.New(mockSvc, ring.Keyfunc)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+
+	h.Route().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var set jwtkeys.JWKS
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "OKP", set.Keys[0].Kty)
+	assert.Equal(t, "Ed25519", set.Keys[0].Crv)
+	assert.NotEmpty(t, set.Keys[0].X)
+}
+
+func TestHandler_RevokeTokens(t *testing.T) {
+	store := revocation.NewMemoryStore(time.Hour)
+	var revokedUsers []int64
+	mockSvc := &mockService{
+		RevokeTokenFunc: func(ctx context.Context, tokenID string, expiresAt time.Time) error {
+			return store.Revoke(ctx, tokenID, expiresAt)
+		},
+		RevokeAllTokensFunc: func(ctx context.Context, userID int64) error {
+			revokedUsers = append(revokedUsers, userID)
+			return nil
+		},
+		RevokeUserTokensFunc: func(ctx context.Context, targetID, userID int64) error {
+			if userID != 1 {
+				return model.ErrNotAdmin
+			}
+			revokedUsers = append(revokedUsers, targetID)
+			return nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc, handler.WithRevocation(store)).Route()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": 2,
+		"jti": "current",
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	send := func(path string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, send("/auth-revoke-all"))
+	assert.Equal(t, []int64{2}, revokedUsers)
+
+	assert.Equal(t, http.StatusForbidden, send("/users/3/revoke-tokens"))
+
+	assert.Equal(t, http.StatusNoContent, send("/auth-revoke"))
+	assert.Equal(t, http.StatusUnauthorized, send("/auth-revoke-all"), "revoked token must be rejected")
+	assert.Equal(t, []int64{2}, revokedUsers)
+}
+
+func TestHandler_UpdateUserRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokenRole  string
+		path       string
+		body       string
+		mockError  error
+		wantRole   string
+		wantStatus int
+	}{
+		{
+			name:       "admin promotes a moderator",
+			tokenRole:  model.RoleAdmin,
+			path:       "/users/3/role",
+			body:       `{"role": "moderator"}`,
+			wantRole:   model.RoleModerator,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "regular user",
+			tokenRole:  model.RoleUser,
+			path:       "/users/3/role",
+			body:       `{"role": "admin"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "moderator cannot manage roles",
+			tokenRole:  model.RoleModerator,
+			path:       "/users/3/role",
+			body:       `{"role": "moderator"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "unknown role",
+			tokenRole:  model.RoleAdmin,
+			path:       "/users/3/role",
+			body:       `{"role": "owner"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid user id",
+			tokenRole:  model.RoleAdmin,
+			path:       "/users/abc/role",
+			body:       `{"role": "user"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "user not found",
+			tokenRole:  model.RoleAdmin,
+			path:       "/users/99/role",
+			body:       `{"role": "user"}`,
+			mockError:  model.ErrUserNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "configured admin",
+			tokenRole:  model.RoleAdmin,
+			path:       "/users/1/role",
+			body:       `{"role": "user"}`,
+			mockError:  model.ErrRoleLocked,
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				SetUserRoleFunc: func(ctx context.Context, targetID int64, role string, userID int64) (*model.User, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return &model.User{ID: targetID, Login: "petr", Role: role}, nil
+				},
+			}
+
+			router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+			signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+				"sub":  1,
+				"role": tt.tokenRole,
+				"exp":  time.Now().Add(time.Hour).Unix(),
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+signed)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantRole != "" {
+				var resp map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, tt.wantRole, resp["role"])
+				assert.Equal(t, float64(3), resp["id"])
 			}
 		})
 	}
@@ -170,7 +674,7 @@ func TestHandler_UserRegistration(t *testing.T) {
 				},
 			}
 
-			h := handler.New(mockSvc, "secret")
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
 
 			req := httptest.NewRequest(http.MethodPost, "/auth-register", strings.NewReader(tt.requestBody))
 			if tt.contentType != "" {
@@ -200,6 +704,7 @@ func TestHandler_CreateAd(t *testing.T) {
 		userID     interface{}
 		mockError  error
 		wantStatus int
+		wantBody   string
 	}{
 		{
 			name: "successful ad creation",
@@ -226,6 +731,54 @@ func TestHandler_CreateAd(t *testing.T) {
 			userID:     int64(1),
 			wantStatus: http.StatusBadRequest,
 		},
+		{
+			name: "gallery without image_url",
+			request: ad.CreateRequest{
+				Title:       "Test Ad",
+				Description: "Description",
+				Images:      []string{"http://example.com/1.jpg", "http://example.com/2.jpg"},
+				Price:       100,
+			},
+			userID:     int64(1),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name: "title rejected by policy",
+			request: ad.CreateRequest{
+				Title:       "Bike @ home",
+				Description: "Description",
+				ImageURL:    "http://example.com/image.jpg",
+				Price:       100,
+			},
+			userID:     int64(1),
+			mockError:  &model.FieldError{Field: "title", Reason: "character '@' is not allowed"},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"title: character '@' is not allowed","field":"title","reason":"character '@' is not allowed"}`,
+		},
+		{
+			name: "description rejected by content policy",
+			request: ad.CreateRequest{
+				Title:       "Bike",
+				Description: "Call +7 912 345-67-89",
+				ImageURL:    "http://example.com/image.jpg",
+				Price:       100,
+			},
+			userID:     int64(1),
+			mockError:  &model.FieldError{Field: "description", Reason: "contains prohibited content", Rule: "phone-number"},
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"description: contains prohibited content","field":"description","reason":"contains prohibited content","rule":"phone-number"}`,
+		},
+		{
+			name: "invalid gallery url",
+			request: ad.CreateRequest{
+				Title:       "Test Ad",
+				Description: "Description",
+				Images:      []string{"not a url"},
+				Price:       100,
+			},
+			userID:     int64(1),
+			wantStatus: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,7 +799,7 @@ func TestHandler_CreateAd(t *testing.T) {
 				},
 			}
 
-			h := handler.New(mockSvc, "secret")
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
 
 			body, _ := json.Marshal(tt.request)
 			req := httptest.NewRequest(http.MethodPost, "/create-ads", bytes.NewReader(body))
@@ -261,6 +814,72 @@ func TestHandler_CreateAd(t *testing.T) {
 			h.CreateAd(w, req)
 
 			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CreateAd_Price(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantPrice  money.Amount
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "decimal number",
+			body:       `{"title":"Test Ad","description":"Description","image_url":"http://example.com/image.jpg","price":19.99}`,
+			wantPrice:  1999,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "decimal string",
+			body:       `{"title":"Test Ad","description":"Description","image_url":"http://example.com/image.jpg","price":"0.10"}`,
+			wantPrice:  10,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "too many decimal places",
+			body:       `{"title":"Test Ad","description":"Description","image_url":"http://example.com/image.jpg","price":1.005}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Validation failed: price: amount must have at most 2 decimal places",
+		},
+		{
+			name:       "above the limit",
+			body:       `{"title":"Test Ad","description":"Description","image_url":"http://example.com/image.jpg","price":1e12}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "Validation failed: price:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got money.Amount
+			mockSvc := &mockService{
+				CreateAdFunc: func(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error) {
+					got = req.Price
+					return &model.Ad{ID: 1, Title: req.Title, Price: req.Price, AuthorID: userID}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodPost, "/create-ads", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), "userID", int64(1)))
+
+			w := httptest.NewRecorder()
+			h.CreateAd(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			} else {
+				assert.Equal(t, tt.wantPrice, got)
+			}
 		})
 	}
 }
@@ -319,8 +938,11 @@ func TestHandler_GetAds(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockSvc := &mockService{
-				GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64) ([]*model.AdWithAuthor, error) {
-					return tt.mockAds, tt.mockError
+				GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return &model.AdList{Ads: tt.mockAds}, nil
 				},
 				ParseListRequestFunc: func(q url.Values) (ad.ListRequest, error) {
 					if tt.parseError != nil {
@@ -335,7 +957,7 @@ func TestHandler_GetAds(t *testing.T) {
 				},
 			}
 
-			h := handler.New(mockSvc, "secret")
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
 
 			req := httptest.NewRequest(http.MethodGet, "/watch-ads?"+tt.queryParams, nil)
 			if tt.userID != nil {
@@ -353,27 +975,28 @@ func TestHandler_GetAds(t *testing.T) {
 func TestHandler_GetAds_ContentCheck(t *testing.T) {
 	mockAds := []*model.AdWithAuthor{
 		{
-			ID:          1,
-			Title:       "Cool Bike",
-			Description: "Fast and red",
-			ImageURL:    "http://image.jpg",
-			Price:       500,
-			AuthorID:    42,
-			AuthorLogin: "biker",
-			CreatedAt:   time.Now(),
+			ID:            1,
+			Title:         "Cool Bike",
+			Description:   "Fast and red",
+			ImageURL:      "http://image.jpg",
+			ImageVariants: model.ImageVariants{Thumbnail: "http://image_thumb.jpg", Medium: "http://image_medium.jpg"},
+			Price:         500,
+			AuthorID:      42,
+			AuthorLogin:   "biker",
+			CreatedAt:     time.Now(),
 		},
 	}
 
 	mockSvc := &mockService{
-		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64) ([]*model.AdWithAuthor, error) {
-			return mockAds, nil
+		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+			return &model.AdList{Ads: mockAds, Total: 11, NextCursor: "next"}, nil
 		},
 		ParseListRequestFunc: func(q url.Values) (ad.ListRequest, error) {
 			return ad.ListRequest{Page: 1, PageSize: 10}, nil
 		},
 	}
 
-	h := handler.New(mockSvc, "secret")
+	h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
 
 	req := httptest.NewRequest(http.MethodGet, "/watch-ads?page=1&page_size=10", nil)
 	req = req.WithContext(context.WithValue(req.Context(), "userID", int64(42))) // same as AuthorID
@@ -383,12 +1006,1724 @@ func TestHandler_GetAds_ContentCheck(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var resp []ad.ListResponse
+	var resp ad.ListPage
 	err := json.NewDecoder(w.Body).Decode(&resp)
 	require.NoError(t, err)
-	require.Len(t, resp, 1)
+	require.Len(t, resp.Items, 1)
+
+	assert.Equal(t, "Cool Bike", resp.Items[0].Title)
+	assert.Equal(t, "biker", resp.Items[0].AuthorLogin)
+	assert.True(t, resp.Items[0].IsOwner)
+	require.NotNil(t, resp.Items[0].ImageVariants)
+	assert.Equal(t, "http://image_thumb.jpg", resp.Items[0].ImageVariants.Thumbnail)
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.Equal(t, int64(11), resp.Total)
+	assert.Equal(t, 2, resp.TotalPages)
+}
+
+func TestHandler_GetAds_EmptyItems(t *testing.T) {
+	mockSvc := &mockService{
+		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+			return &model.AdList{}, nil
+		},
+		ParseListRequestFunc: func(q url.Values) (ad.ListRequest, error) {
+			return ad.ListRequest{Page: 3, PageSize: 10, SortBy: "price", SortOrder: "asc"}, nil
+		},
+	}
+
+	h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+	req := httptest.NewRequest(http.MethodGet, "/watch-ads?page=3", nil)
+	w := httptest.NewRecorder()
+
+	h.GetAds(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items":[]`)
+
+	var resp ad.ListPage
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, 3, resp.Page)
+	assert.Equal(t, 10, resp.PageSize)
+	assert.Equal(t, int64(0), resp.Total)
+	assert.Equal(t, 0, resp.TotalPages)
+	assert.Equal(t, "price", resp.SortBy)
+	assert.Equal(t, "asc", resp.SortOrder)
+}
+
+func TestHandler_GetAd(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         string
+		userID     interface{}
+		mockError  error
+		wantStatus int
+		wantOwner  bool
+	}{
+		{
+			name:       "owner views ad",
+			id:         "1",
+			userID:     int64(42),
+			wantStatus: http.StatusOK,
+			wantOwner:  true,
+		},
+		{
+			name:       "anonymous views ad",
+			id:         "1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid id",
+			id:         "abc",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not found",
+			id:         "2",
+			mockError:  model.ErrAdNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				GetVisibleAdFunc: func(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return &model.AdWithAuthor{
+						ID:          id,
+						Title:       "Cool Bike",
+						AuthorID:    42,
+						AuthorLogin: "biker",
+						Status:      model.AdStatusPublished,
+					}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodGet, "/ads/"+tt.id, nil)
+			req.SetPathValue("id", tt.id)
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "userID", tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			h.GetAd(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var resp ad.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, "biker", resp.AuthorLogin)
+				assert.Equal(t, tt.wantOwner, resp.IsOwner)
+				assert.Equal(t, model.AdStatusPublished, resp.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_Moderation(t *testing.T) {
+	pending := func(id int64) *model.AdWithAuthor {
+		return &model.AdWithAuthor{ID: id, Title: "Cool Bike", AuthorID: 42, Status: model.AdStatusPending}
+	}
+
+	var gotReason string
+	var gotPage, gotPageSize int
+	mockSvc := &mockService{
+		ModerationQueueFunc: func(ctx context.Context, page, pageSize int, userID int64) (*model.AdList, error) {
+			gotPage, gotPageSize = page, pageSize
+			return &model.AdList{Ads: []*model.AdWithAuthor{pending(1), pending(2)}, Total: 3}, nil
+		},
+		ApproveAdFunc: func(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+			if id == 2 {
+				return nil, fmt.Errorf("%w: published to published", model.ErrInvalidAdTransition)
+			}
+			ad := pending(id)
+			ad.Status = model.AdStatusPublished
+			return ad, nil
+		},
+		RejectAdFunc: func(ctx context.Context, id int64, reason string, userID int64) (*model.AdWithAuthor, error) {
+			gotReason = reason
+			ad := pending(id)
+			ad.Status = model.AdStatusRejected
+			ad.RejectionReason = reason
+			return ad, nil
+		},
+		ArchiveAdFunc: func(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+			if userID != 42 {
+				return nil, model.ErrNotAdAuthor
+			}
+			ad := pending(id)
+			ad.Status = model.AdStatusArchived
+			return ad, nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	tokenFor := func(userID int64, role string) string {
+		signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+			"sub":  userID,
+			"role": role,
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantState  string
+	}{
+		{
+			name:       "queue for moderator",
+			method:     http.MethodGet,
+			path:       "/moderation/ads?page=2&page_size=2",
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "queue for regular user",
+			method:     http.MethodGet,
+			path:       "/moderation/ads",
+			token:      tokenFor(42, model.RoleUser),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "queue with invalid page size",
+			method:     http.MethodGet,
+			path:       "/moderation/ads?page_size=500",
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "approve",
+			method:     http.MethodPost,
+			path:       "/ads/1/approve",
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusOK,
+			wantState:  model.AdStatusPublished,
+		},
+		{
+			name:       "approve already published",
+			method:     http.MethodPost,
+			path:       "/ads/2/approve",
+			token:      tokenFor(7, model.RoleAdmin),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "author cannot approve",
+			method:     http.MethodPost,
+			path:       "/ads/1/approve",
+			token:      tokenFor(42, model.RoleUser),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "reject with reason",
+			method:     http.MethodPost,
+			path:       "/ads/1/reject",
+			body:       `{"reason": "prohibited item"}`,
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusOK,
+			wantState:  model.AdStatusRejected,
+		},
+		{
+			name:       "reject without reason",
+			method:     http.MethodPost,
+			path:       "/ads/1/reject",
+			body:       `{}`,
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "author archives",
+			method:     http.MethodPost,
+			path:       "/ads/1/archive",
+			token:      tokenFor(42, model.RoleUser),
+			wantStatus: http.StatusOK,
+			wantState:  model.AdStatusArchived,
+		},
+		{
+			name:       "stranger cannot archive",
+			method:     http.MethodPost,
+			path:       "/ads/1/archive",
+			token:      tokenFor(5, model.RoleUser),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantState != "" {
+				var resp ad.Response
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				assert.Equal(t, tt.wantState, resp.Status)
+			}
+		})
+	}
+
+	assert.Equal(t, "prohibited item", gotReason)
+	assert.Equal(t, 2, gotPage)
+	assert.Equal(t, 2, gotPageSize)
+}
+
+func TestHandler_Reports(t *testing.T) {
+	var dismissed int64
+	mockSvc := &mockService{
+		ReportAdFunc: func(ctx context.Context, adID int64, reason, comment string, userID int64) (*model.Report, error) {
+			switch {
+			case userID == 42:
+				return nil, model.ErrReportOwnAd
+			case adID == 2:
+				return nil, model.ErrDuplicateReport
+			case adID == 3:
+				return nil, model.ErrAdNotFound
+			}
+			return &model.Report{ID: 1, AdID: adID, ReporterID: userID, Reason: reason, Comment: comment, Status: model.ReportStatusOpen}, nil
+		},
+		GetReportedAdsFunc: func(ctx context.Context, page, pageSize int, userID int64) (*model.ReportedAdList, error) {
+			hiddenAt := time.Now()
+			return &model.ReportedAdList{
+				Items: []*model.ReportedAd{{
+					Ad:          &model.AdWithAuthor{ID: 1, AuthorID: 42, Status: model.AdStatusPublished, HiddenAt: &hiddenAt},
+					OpenReports: 3,
+					Reasons:     []string{"scam", "spam"},
+				}},
+				Total: 1,
+			}, nil
+		},
+		DismissReportsFunc: func(ctx context.Context, adID, userID int64) error {
+			dismissed = adID
+			return nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	tokenFor := func(userID int64, role string) string {
+		signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+			"sub":  userID,
+			"role": role,
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+	}{
+		{
+			name:       "report",
+			method:     http.MethodPost,
+			path:       "/ads/1/reports",
+			body:       `{"reason": "scam", "comment": "asks for prepayment"}`,
+			token:      tokenFor(5, model.RoleUser),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "unknown reason",
+			method:     http.MethodPost,
+			path:       "/ads/1/reports",
+			body:       `{"reason": "ugly"}`,
+			token:      tokenFor(5, model.RoleUser),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "own ad",
+			method:     http.MethodPost,
+			path:       "/ads/1/reports",
+			body:       `{"reason": "spam"}`,
+			token:      tokenFor(42, model.RoleUser),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "already reported",
+			method:     http.MethodPost,
+			path:       "/ads/2/reports",
+			body:       `{"reason": "spam"}`,
+			token:      tokenFor(5, model.RoleUser),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "ad not found",
+			method:     http.MethodPost,
+			path:       "/ads/3/reports",
+			body:       `{"reason": "spam"}`,
+			token:      tokenFor(5, model.RoleUser),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "reported ads for moderator",
+			method:     http.MethodGet,
+			path:       "/moderation/reports",
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reported ads for regular user",
+			method:     http.MethodGet,
+			path:       "/moderation/reports",
+			token:      tokenFor(5, model.RoleUser),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "dismiss",
+			method:     http.MethodPost,
+			path:       "/ads/1/reports/dismiss",
+			token:      tokenFor(7, model.RoleModerator),
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.path == "/moderation/reports" && w.Code == http.StatusOK {
+				assert.Contains(t, w.Body.String(), `"open_reports":3`)
+				assert.Contains(t, w.Body.String(), `"hidden":true`)
+			}
+		})
+	}
+
+	assert.Equal(t, int64(1), dismissed)
+}
+
+func TestHandler_Favorites(t *testing.T) {
+	favorites := map[int64]bool{}
+	var gotCurrency string
+	mockSvc := &mockService{
+		AddFavoriteFunc: func(ctx context.Context, adID, userID int64) error {
+			if adID == 3 {
+				return model.ErrAdNotFound
+			}
+			favorites[adID] = true
+			return nil
+		},
+		RemoveFavoriteFunc: func(ctx context.Context, adID, userID int64) error {
+			delete(favorites, adID)
+			return nil
+		},
+		GetFavoritesFunc: func(ctx context.Context, page, pageSize int, currency string, userID int64) (*model.AdList, error) {
+			gotCurrency = currency
+			return &model.AdList{
+				Ads: []*model.AdWithAuthor{{
+					ID:           1,
+					Title:        "Bike",
+					Price:        mustAmount("100"),
+					Currency:     "RUB",
+					DisplayPrice: mustAmount("100"),
+					AuthorID:     42,
+					AuthorLogin:  "petr",
+					IsFavorite:   true,
+				}},
+				Total: 1,
+			}, nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+		"sub": 5,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "add", method: http.MethodPut, path: "/ads/1/favorite", token: signed, wantStatus: http.StatusNoContent},
+		{name: "add again", method: http.MethodPut, path: "/ads/1/favorite", token: signed, wantStatus: http.StatusNoContent},
+		{name: "add missing ad", method: http.MethodPut, path: "/ads/3/favorite", token: signed, wantStatus: http.StatusNotFound},
+		{name: "invalid ad id", method: http.MethodPut, path: "/ads/abc/favorite", token: signed, wantStatus: http.StatusBadRequest},
+		{name: "anonymous", method: http.MethodPut, path: "/ads/1/favorite", wantStatus: http.StatusUnauthorized},
+		{name: "remove", method: http.MethodDelete, path: "/ads/2/favorite", token: signed, wantStatus: http.StatusNoContent},
+		{
+			name:       "list",
+			method:     http.MethodGet,
+			path:       "/me/favorites?page=1&page_size=10",
+			token:      signed,
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"title":"Bike","description":"","image_url":"","price":100,"currency":"RUB",
+				"display_price":100,"author_login":"petr","author_rating":null,"is_owner":false,"is_favorite":true}],
+				"page":1,"page_size":10,"total":1,"total_pages":1,"currency":"RUB"}`,
+		},
+		{name: "list with bad currency", method: http.MethodGet, path: "/me/favorites?currency=rubles", token: signed, wantStatus: http.StatusBadRequest},
+		{name: "list with bad page size", method: http.MethodGet, path: "/me/favorites?page_size=1000", token: signed, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, map[int64]bool{1: true}, favorites)
+	assert.Equal(t, "", gotCurrency)
+}
 
-	assert.Equal(t, "Cool Bike", resp[0].Title)
-	assert.Equal(t, "biker", resp[0].AuthorLogin)
-	assert.True(t, resp[0].IsOwner)
+func TestHandler_Threads(t *testing.T) {
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	participant := func(threadID, userID int64) error {
+		switch {
+		case threadID == 9:
+			return model.ErrThreadNotFound
+		case userID != 5 && userID != 42:
+			return model.ErrNotThreadParticipant
+		}
+		return nil
+	}
+
+	var gotAfterID int64
+	var gotLimit int
+	mockSvc := &mockService{
+		StartThreadFunc: func(ctx context.Context, adID int64, message string, userID int64) (*model.Thread, bool, error) {
+			switch {
+			case userID == 42:
+				return nil, false, model.ErrMessageOwnAd
+			case adID == 3:
+				return nil, false, model.ErrAdNotFound
+			}
+			msg := &model.Message{ID: 1, ThreadID: 7, SenderID: userID, Body: message, CreatedAt: createdAt}
+			return &model.Thread{
+				ID: 7, AdID: adID, AdTitle: "Bike",
+				BuyerID: userID, BuyerLogin: "pavel", SellerID: 42, SellerLogin: "petr",
+				CreatedAt: createdAt, LastMessageAt: createdAt, LastMessage: msg,
+			}, adID == 1, nil
+		},
+		GetThreadsFunc: func(ctx context.Context, page, pageSize int, userID int64) (*model.ThreadList, error) {
+			return &model.ThreadList{
+				Items: []*model.Thread{{ID: 7, AdID: 1, BuyerID: 5, SellerID: userID, Unread: 2}},
+				Total: 1, Unread: 2,
+			}, nil
+		},
+		GetMessagesFunc: func(ctx context.Context, threadID, afterID int64, limit int, userID int64) ([]model.Message, error) {
+			if err := participant(threadID, userID); err != nil {
+				return nil, err
+			}
+			gotAfterID, gotLimit = afterID, limit
+			return []model.Message{{ID: 2, ThreadID: threadID, SenderID: 42, Body: "Still available", CreatedAt: createdAt}}, nil
+		},
+		PostMessageFunc: func(ctx context.Context, threadID int64, message string, userID int64) (*model.Message, error) {
+			if err := participant(threadID, userID); err != nil {
+				return nil, err
+			}
+			return &model.Message{ID: 3, ThreadID: threadID, SenderID: userID, Body: message, CreatedAt: createdAt}, nil
+		},
+		MarkThreadReadFunc: func(ctx context.Context, threadID, userID int64) error {
+			return participant(threadID, userID)
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	tokenFor := func(userID int64) string {
+		signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+			"sub": userID,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "start thread",
+			method:     http.MethodPost,
+			path:       "/ads/1/threads",
+			body:       `{"message": "Is it available?"}`,
+			token:      tokenFor(5),
+			wantStatus: http.StatusCreated,
+			wantBody: `{"id":7,"ad_id":1,"ad_title":"Bike","buyer":{"id":5,"login":"pavel"},"seller":{"id":42,"login":"petr"},
+				"last_message":{"id":1,"thread_id":7,"sender_id":5,"body":"Is it available?","is_mine":true,"created_at":"2026-10-17T12:00:00Z"},
+				"unread":0,"created_at":"2026-10-17T12:00:00Z","last_message_at":"2026-10-17T12:00:00Z"}`,
+		},
+		{name: "existing thread", method: http.MethodPost, path: "/ads/2/threads", body: `{"message": "Hello again"}`, token: tokenFor(5), wantStatus: http.StatusOK},
+		{name: "empty message", method: http.MethodPost, path: "/ads/1/threads", body: `{"message": ""}`, token: tokenFor(5), wantStatus: http.StatusBadRequest},
+		{name: "own ad", method: http.MethodPost, path: "/ads/1/threads", body: `{"message": "Hi"}`, token: tokenFor(42), wantStatus: http.StatusBadRequest},
+		{name: "missing ad", method: http.MethodPost, path: "/ads/3/threads", body: `{"message": "Hi"}`, token: tokenFor(5), wantStatus: http.StatusNotFound},
+		{name: "anonymous", method: http.MethodPost, path: "/ads/1/threads", body: `{"message": "Hi"}`, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "list threads",
+			method:     http.MethodGet,
+			path:       "/threads",
+			token:      tokenFor(42),
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[{"id":7,"ad_id":1,"ad_title":"","buyer":{"id":5,"login":""},"seller":{"id":42,"login":""},
+				"unread":2,"created_at":"0001-01-01T00:00:00Z","last_message_at":"0001-01-01T00:00:00Z"}],
+				"page":1,"page_size":20,"total":1,"total_pages":1,"unread":2}`,
+		},
+		{
+			name:       "read messages",
+			method:     http.MethodGet,
+			path:       "/threads/7/messages?after_id=1&limit=10",
+			token:      tokenFor(5),
+			wantStatus: http.StatusOK,
+			wantBody:   `{"items":[{"id":2,"thread_id":7,"sender_id":42,"body":"Still available","is_mine":false,"created_at":"2026-10-17T12:00:00Z"}]}`,
+		},
+		{name: "messages of another thread", method: http.MethodGet, path: "/threads/7/messages", token: tokenFor(6), wantStatus: http.StatusForbidden},
+		{name: "messages with bad limit", method: http.MethodGet, path: "/threads/7/messages?limit=1000", token: tokenFor(5), wantStatus: http.StatusBadRequest},
+		{name: "missing thread", method: http.MethodGet, path: "/threads/9/messages", token: tokenFor(5), wantStatus: http.StatusNotFound},
+		{name: "post message", method: http.MethodPost, path: "/threads/7/messages", body: `{"body": "Deal"}`, token: tokenFor(42), wantStatus: http.StatusCreated},
+		{name: "post to another thread", method: http.MethodPost, path: "/threads/7/messages", body: `{"body": "Hi"}`, token: tokenFor(6), wantStatus: http.StatusForbidden},
+		{name: "mark read", method: http.MethodPost, path: "/threads/7/read", token: tokenFor(42), wantStatus: http.StatusNoContent},
+		{name: "invalid thread id", method: http.MethodPost, path: "/threads/abc/read", token: tokenFor(42), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, int64(1), gotAfterID)
+	assert.Equal(t, 10, gotLimit)
+}
+
+func TestHandler_Reviews(t *testing.T) {
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	rating := 4.5
+
+	var gotPage, gotPageSize int
+	mockSvc := &mockService{
+		CreateReviewFunc: func(ctx context.Context, adID int64, rating int, text string, userID int64) (*model.Review, error) {
+			switch {
+			case userID == 42:
+				return nil, model.ErrReviewOwnAd
+			case userID == 6:
+				return nil, model.ErrReviewNotAllowed
+			case adID == 2:
+				return nil, model.ErrDuplicateReview
+			case adID == 3:
+				return nil, model.ErrAdNotFound
+			}
+			return &model.Review{
+				ID: 1, AdID: adID, AdTitle: "Bike", SellerID: 42,
+				ReviewerID: userID, ReviewerLogin: "pavel", Rating: rating, Text: text, CreatedAt: createdAt,
+			}, nil
+		},
+		GetProfileFunc: func(ctx context.Context, login string) (*model.Profile, error) {
+			switch login {
+			case "petr":
+				return &model.Profile{ID: 42, Login: login, Rating: &rating, ReviewsCount: 2, CreatedAt: createdAt}, nil
+			case "newbie":
+				return &model.Profile{ID: 43, Login: login, CreatedAt: createdAt}, nil
+			}
+			return nil, model.ErrUserNotFound
+		},
+		GetReviewsFunc: func(ctx context.Context, login string, page, pageSize int) (*model.ReviewList, error) {
+			if login != "petr" {
+				return nil, model.ErrUserNotFound
+			}
+			gotPage, gotPageSize = page, pageSize
+			return &model.ReviewList{
+				Items: []model.Review{{ID: 1, ReviewerID: 5, ReviewerLogin: "pavel", AdTitle: "Old bike", Rating: 5, CreatedAt: createdAt}},
+				Total: 2,
+			}, nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	tokenFor := func(userID int64) string {
+		signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+			"sub": userID,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "leave review",
+			method:     http.MethodPost,
+			path:       "/ads/1/reviews",
+			body:       `{"rating": 5, "text": "Fast and friendly"}`,
+			token:      tokenFor(5),
+			wantStatus: http.StatusCreated,
+			wantBody: `{"id":1,"ad_id":1,"ad_title":"Bike","reviewer":{"id":5,"login":"pavel"},"rating":5,
+				"text":"Fast and friendly","created_at":"2026-10-17T12:00:00Z"}`,
+		},
+		{name: "rating out of range", method: http.MethodPost, path: "/ads/1/reviews", body: `{"rating": 0}`, token: tokenFor(5), wantStatus: http.StatusBadRequest},
+		{name: "own ad", method: http.MethodPost, path: "/ads/1/reviews", body: `{"rating": 5}`, token: tokenFor(42), wantStatus: http.StatusBadRequest},
+		{name: "not a buyer", method: http.MethodPost, path: "/ads/1/reviews", body: `{"rating": 1}`, token: tokenFor(6), wantStatus: http.StatusForbidden},
+		{name: "second review", method: http.MethodPost, path: "/ads/2/reviews", body: `{"rating": 1}`, token: tokenFor(5), wantStatus: http.StatusConflict},
+		{name: "missing ad", method: http.MethodPost, path: "/ads/3/reviews", body: `{"rating": 1}`, token: tokenFor(5), wantStatus: http.StatusNotFound},
+		{name: "anonymous", method: http.MethodPost, path: "/ads/1/reviews", body: `{"rating": 5}`, wantStatus: http.StatusUnauthorized},
+		{
+			name:       "seller profile",
+			method:     http.MethodGet,
+			path:       "/users/petr",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":42,"login":"petr","display_name":"","about":"","avatar_url":"","location":"","rating":4.5,"reviews_count":2,"member_since":"2026-10-17T12:00:00Z"}`,
+		},
+		{
+			name:       "profile without reviews",
+			method:     http.MethodGet,
+			path:       "/users/newbie",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":43,"login":"newbie","display_name":"","about":"","avatar_url":"","location":"","rating":null,"reviews_count":0,"member_since":"2026-10-17T12:00:00Z"}`,
+		},
+		{name: "missing profile", method: http.MethodGet, path: "/users/nobody", wantStatus: http.StatusNotFound},
+		{
+			name:       "seller reviews",
+			method:     http.MethodGet,
+			path:       "/users/petr/reviews?page=2&page_size=1",
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"ad_title":"Old bike","reviewer":{"id":5,"login":"pavel"},"rating":5,"text":"",
+				"created_at":"2026-10-17T12:00:00Z"}],"page":2,"page_size":1,"total":2,"total_pages":2}`,
+		},
+		{name: "reviews of missing user", method: http.MethodGet, path: "/users/nobody/reviews", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, 2, gotPage)
+	assert.Equal(t, 1, gotPageSize)
+}
+
+func TestHandler_Profiles(t *testing.T) {
+	createdAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	profile := model.Profile{
+		ID:              42,
+		Login:           "petr",
+		Role:            model.RoleUser,
+		DisplayName:     "Petr",
+		Location:        "Kazan",
+		Email:           "petr@example.com",
+		EmailVerifiedAt: &createdAt,
+		CreatedAt:       createdAt,
+	}
+
+	var gotReq ad.ListRequest
+	mockSvc := &mockService{
+		GetMeFunc: func(ctx context.Context, userID int64) (*model.Profile, error) {
+			if userID != 42 {
+				return nil, model.ErrUserNotFound
+			}
+			p := profile
+			return &p, nil
+		},
+		UpdateProfileFunc: func(ctx context.Context, req user.UpdateProfileRequest, userID int64) (*model.Profile, error) {
+			if req.AvatarURL != nil {
+				return nil, model.ErrImageNotUploaded
+			}
+			p := profile
+			if req.About != nil {
+				p.About = *req.About
+			}
+			return &p, nil
+		},
+		ParseListRequestFunc: func(q url.Values) (ad.ListRequest, error) {
+			return ad.ListRequest{Page: 1, PageSize: 10, SortBy: q.Get("sort_by")}, nil
+		},
+		GetUserAdsFunc: func(ctx context.Context, login string, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+			if login != "petr" {
+				return nil, model.ErrUserNotFound
+			}
+			gotReq = *req
+			return &model.AdList{
+				Ads:   []*model.AdWithAuthor{{ID: 1, Title: "Bike", AuthorID: 42, AuthorLogin: "petr", Currency: "RUB"}},
+				Total: 1,
+			}, nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	tokenFor := func(userID int64) string {
+		signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+			"sub": userID,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "current user",
+			method:     http.MethodGet,
+			path:       "/me",
+			token:      tokenFor(42),
+			wantStatus: http.StatusOK,
+			wantBody: `{"id":42,"login":"petr","display_name":"Petr","about":"","avatar_url":"","location":"Kazan",
+				"rating":null,"reviews_count":0,"member_since":"2026-10-17T12:00:00Z","role":"user",
+				"email":"petr@example.com","email_verified":true}`,
+		},
+		{name: "current user anonymous", method: http.MethodGet, path: "/me", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "update profile",
+			method:     http.MethodPatch,
+			path:       "/me",
+			body:       `{"about": "Selling my bikes"}`,
+			token:      tokenFor(42),
+			wantStatus: http.StatusOK,
+			wantBody: `{"id":42,"login":"petr","display_name":"Petr","about":"Selling my bikes","avatar_url":"","location":"Kazan",
+				"rating":null,"reviews_count":0,"member_since":"2026-10-17T12:00:00Z","role":"user",
+				"email":"petr@example.com","email_verified":true}`,
+		},
+		{name: "foreign avatar", method: http.MethodPatch, path: "/me", body: `{"avatar_url": "https://example.com/a.png"}`, token: tokenFor(42), wantStatus: http.StatusBadRequest},
+		{name: "display name too long", method: http.MethodPatch, path: "/me", body: `{"display_name": "` + strings.Repeat("a", 51) + `"}`, token: tokenFor(42), wantStatus: http.StatusBadRequest},
+		{
+			name:       "seller ads",
+			method:     http.MethodGet,
+			path:       "/users/petr/ads?sort_by=price",
+			token:      tokenFor(5),
+			wantStatus: http.StatusOK,
+			wantBody: `{"items":[{"id":1,"title":"Bike","description":"","image_url":"","price":0,"currency":"RUB","display_price":0,
+				"author_login":"petr","author_rating":null,"is_owner":false,"is_favorite":false}],
+				"page":1,"page_size":10,"total":1,"total_pages":1,"sort_by":"price","sort_order":"","currency":""}`,
+		},
+		{name: "ads of missing seller", method: http.MethodGet, path: "/users/nobody/ads", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, "price", gotReq.SortBy)
+}
+
+func TestHandler_Account(t *testing.T) {
+	var deleted []int64
+	mockSvc := &mockService{
+		ChangePasswordFunc: func(ctx context.Context, currentPassword, newPassword string, userID int64) error {
+			if currentPassword != "password" {
+				return model.ErrWrongPassword
+			}
+			if newPassword == currentPassword {
+				return &model.FieldError{Field: "new_password", Reason: "must differ from the current password"}
+			}
+			return nil
+		},
+		RequestResetFunc: func(ctx context.Context, login string) error {
+			if login == "offline" {
+				return model.ErrPasswordResetDisabled
+			}
+			return nil
+		},
+		ResetPasswordFunc: func(ctx context.Context, token, newPassword string) error {
+			if token != "valid-token" {
+				return model.ErrInvalidResetToken
+			}
+			return nil
+		},
+		DeleteAccountFunc: func(ctx context.Context, password string, userID int64) error {
+			if password != "password" {
+				return model.ErrWrongPassword
+			}
+			deleted = append(deleted, userID)
+			return nil
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+		"sub": 5,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "change password", method: http.MethodPost, path: "/me/password", body: `{"current_password": "password", "new_password": "new-password"}`, token: signed, wantStatus: http.StatusNoContent},
+		{name: "wrong current password", method: http.MethodPost, path: "/me/password", body: `{"current_password": "guess", "new_password": "new-password"}`, token: signed, wantStatus: http.StatusForbidden},
+		{
+			name:       "same password",
+			method:     http.MethodPost,
+			path:       "/me/password",
+			body:       `{"current_password": "password", "new_password": "password"}`,
+			token:      signed,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"new_password: must differ from the current password","field":"new_password","reason":"must differ from the current password"}`,
+		},
+		{name: "short new password", method: http.MethodPost, path: "/me/password", body: `{"current_password": "password", "new_password": "123"}`, token: signed, wantStatus: http.StatusBadRequest},
+		{name: "change password anonymously", method: http.MethodPost, path: "/me/password", body: `{"current_password": "password", "new_password": "new-password"}`, wantStatus: http.StatusUnauthorized},
+		{name: "request reset", method: http.MethodPost, path: "/auth-password-reset", body: `{"login": "petr"}`, wantStatus: http.StatusAccepted},
+		{name: "request reset without notifier", method: http.MethodPost, path: "/auth-password-reset", body: `{"login": "offline"}`, wantStatus: http.StatusServiceUnavailable},
+		{name: "confirm reset", method: http.MethodPost, path: "/auth-password-reset-confirm", body: `{"token": "valid-token", "new_password": "new-password"}`, wantStatus: http.StatusNoContent},
+		{name: "confirm reset with bad token", method: http.MethodPost, path: "/auth-password-reset-confirm", body: `{"token": "stale", "new_password": "new-password"}`, wantStatus: http.StatusBadRequest},
+		{name: "delete with wrong password", method: http.MethodDelete, path: "/me", body: `{"password": "guess"}`, token: signed, wantStatus: http.StatusForbidden},
+		{name: "delete without password", method: http.MethodDelete, path: "/me", body: `{}`, token: signed, wantStatus: http.StatusBadRequest},
+		{name: "delete account", method: http.MethodDelete, path: "/me", body: `{"password": "password"}`, token: signed, wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, []int64{5}, deleted)
+}
+
+func TestHandler_Email(t *testing.T) {
+	var verified []string
+	mockSvc := &mockService{
+		RegisterUserFunc: func(ctx context.Context, req *auth.RegistrationRequest) (*auth.RegistrationResponse, error) {
+			if req.Email == "taken@example.com" {
+				return nil, model.ErrEmailTaken
+			}
+			resp := &auth.RegistrationResponse{ID: 1, Login: req.Login, Email: req.Email}
+			if req.Email != "" {
+				sent := req.Email != "unsent@example.com"
+				resp.EmailVerificationSent = &sent
+			}
+			return resp, nil
+		},
+		SetEmailFunc: func(ctx context.Context, email string, userID int64) error {
+			switch email {
+			case "taken@example.com":
+				return model.ErrEmailTaken
+			case "petr@example.com":
+				return model.ErrEmailAlreadyVerified
+			}
+			return nil
+		},
+		VerifyEmailFunc: func(ctx context.Context, token string) error {
+			if token != "valid-token" {
+				return model.ErrInvalidVerificationToken
+			}
+			verified = append(verified, token)
+			return nil
+		},
+		CreateAdFunc: func(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error) {
+			return nil, model.ErrEmailNotVerified
+		},
+	}
+
+	router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+	signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+		"sub": 5,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "register with email",
+			path:       "/auth-register",
+			body:       `{"login": "petr", "password": "password", "email": "petr@example.com"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":1,"login":"petr","email":"petr@example.com","email_verification_sent":true}`,
+		},
+		{
+			name:       "register when mail is not sent",
+			path:       "/auth-register",
+			body:       `{"login": "petr", "password": "password", "email": "unsent@example.com"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":1,"login":"petr","email":"unsent@example.com","email_verification_sent":false}`,
+		},
+		{
+			name:       "register without email",
+			path:       "/auth-register",
+			body:       `{"login": "petr", "password": "password"}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":1,"login":"petr"}`,
+		},
+		{name: "register with invalid email", path: "/auth-register", body: `{"login": "petr", "password": "password", "email": "petr"}`, wantStatus: http.StatusBadRequest},
+		{name: "register with taken email", path: "/auth-register", body: `{"login": "petr", "password": "password", "email": "taken@example.com"}`, wantStatus: http.StatusConflict},
+		{name: "set email", path: "/me/email", body: `{"email": "new@example.com"}`, token: signed, wantStatus: http.StatusAccepted},
+		{name: "set taken email", path: "/me/email", body: `{"email": "taken@example.com"}`, token: signed, wantStatus: http.StatusConflict},
+		{name: "set verified email", path: "/me/email", body: `{"email": "petr@example.com"}`, token: signed, wantStatus: http.StatusConflict},
+		{name: "set email anonymously", path: "/me/email", body: `{"email": "new@example.com"}`, wantStatus: http.StatusUnauthorized},
+		{name: "verify email", path: "/auth-verify-email", body: `{"token": "valid-token"}`, wantStatus: http.StatusNoContent},
+		{name: "verify with bad token", path: "/auth-verify-email", body: `{"token": "stale"}`, wantStatus: http.StatusBadRequest},
+		{
+			name:       "create ad unverified",
+			path:       "/create-ads",
+			body:       `{"title": "Bike", "description": "Good bike", "image_url": "http://localhost/static/images/bike.jpg", "price": 100}`,
+			token:      signed,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"valid-token"}, verified)
+}
+
+func TestHandler_StreamEvents(t *testing.T) {
+	hub := events.NewHub(10, time.Minute)
+	router := handler.New(&mockService{}, jwtkeys.HMAC("secret").Keyfunc, handler.WithEvents(hub, 50*time.Millisecond)).Route()
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+		"sub": 5,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	hub.Publish(5, events.TypeAdStatus, events.AdStatusPayload{AdID: 1, Status: model.AdStatusPublished})
+	hub.Publish(5, events.TypePriceDrop, events.PriceDropPayload{AdID: 2, NewPrice: mustAmount("90")})
+
+	connect := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+signed)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		return resp, bufio.NewReader(resp.Body)
+	}
+	readFrame := func(r *bufio.Reader) string {
+		var frame strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return frame.String()
+			}
+			frame.WriteString(line)
+		}
+	}
+
+	resp, r := connect("")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "retry: 3000\n", readFrame(r))
+
+	hub.Publish(5, events.TypeMessage, events.MessagePayload{ThreadID: 7, Body: "Hi"})
+	frame := readFrame(r)
+	assert.Contains(t, frame, "event: message\n")
+	assert.Contains(t, frame, `data: {"thread_id":7,`)
+	assert.Equal(t, ": ping\n", readFrame(r))
+	resp.Body.Close()
+
+	var firstID string
+	resp, r = connect("1")
+	readFrame(r)
+	for _, eventType := range []string{events.TypeAdStatus, events.TypePriceDrop, events.TypeMessage} {
+		frame := readFrame(r)
+		assert.Contains(t, frame, "event: "+eventType+"\n")
+		if firstID == "" {
+			firstID = strings.TrimPrefix(strings.SplitN(frame, "\n", 2)[0], "id: ")
+		}
+	}
+	resp.Body.Close()
+
+	resp, r = connect(firstID)
+	readFrame(r)
+	assert.Contains(t, readFrame(r), "event: price_drop\n", "replay starts after Last-Event-ID")
+	resp.Body.Close()
+
+	resp, _ = connect("abc")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp.Body.Close()
+
+	resp, err = server.Client().Get(server.URL + "/events")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestHandler_UpdateAd(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestBody string
+		userID      interface{}
+		mockError   error
+		wantStatus  int
+	}{
+		{
+			name:        "successful update",
+			requestBody: `{"price":150}`,
+			userID:      int64(1),
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "unauthorized",
+			requestBody: `{"price":150}`,
+			wantStatus:  http.StatusUnauthorized,
+		},
+		{
+			name:        "invalid data",
+			requestBody: `{"price":-1}`,
+			userID:      int64(1),
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "empty title",
+			requestBody: `{"title":""}`,
+			userID:      int64(1),
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "empty description",
+			requestBody: `{"description":""}`,
+			userID:      int64(1),
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "not the author",
+			requestBody: `{"price":150}`,
+			userID:      int64(2),
+			mockError:   model.ErrNotAdAuthor,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "not found",
+			requestBody: `{"price":150}`,
+			userID:      int64(1),
+			mockError:   model.ErrAdNotFound,
+			wantStatus:  http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				UpdateAdFunc: func(ctx context.Context, id int64, req ad.UpdateRequest, userID int64) (*model.Ad, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return &model.Ad{ID: id, Price: *req.Price, AuthorID: userID}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodPatch, "/ads/1", strings.NewReader(tt.requestBody))
+			req.SetPathValue("id", "1")
+			req.Header.Set("Content-Type", "application/json")
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "userID", tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			h.UpdateAd(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_DeleteAd(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     interface{}
+		mockError  error
+		wantStatus int
+	}{
+		{
+			name:       "successful delete",
+			userID:     int64(1),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "unauthorized",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "not the author",
+			userID:     int64(2),
+			mockError:  model.ErrNotAdAuthor,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				DeleteAdFunc: func(ctx context.Context, id int64, userID int64) error {
+					return tt.mockError
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodDelete, "/ads/1", nil)
+			req.SetPathValue("id", "1")
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "userID", tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			h.DeleteAd(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_GetCategories(t *testing.T) {
+	mockSvc := &mockService{
+		GetCategoryTreeFunc: func(ctx context.Context) ([]*model.Category, error) {
+			return []*model.Category{
+				{
+					ID:   1,
+					Name: "Electronics",
+					Slug: "electronics",
+					Children: []*model.Category{
+						{ID: 2, Name: "Phones", Slug: "phones", ParentID: 1},
+					},
+				},
+			}, nil
+		},
+	}
+
+	h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+	req := httptest.NewRequest(http.MethodGet, "/categories", nil)
+	w := httptest.NewRecorder()
+
+	h.GetCategories(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp []category.Node
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	require.Len(t, resp, 1)
+	require.Len(t, resp[0].Children, 1)
+	assert.Equal(t, "phones", resp[0].Children[0].Slug)
+	assert.NotNil(t, resp[0].Children[0].Children)
+}
+
+func TestHandler_UploadImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		userID     interface{}
+		mockError  error
+		wantStatus int
+	}{
+		{
+			name:       "successful upload",
+			field:      "image",
+			userID:     int64(1),
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "unauthorized",
+			field:      "image",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing file",
+			field:      "file",
+			userID:     int64(1),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported format",
+			field:      "image",
+			userID:     int64(1),
+			mockError:  model.ErrUnsupportedImageType,
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "too large",
+			field:      "image",
+			userID:     int64(1),
+			mockError:  model.ErrImageTooLarge,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				UploadImageFunc: func(ctx context.Context, r io.Reader, userID int64) (*model.Image, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					data, err := io.ReadAll(r)
+					require.NoError(t, err)
+					assert.Equal(t, "fake-image-bytes", string(data))
+					return &model.Image{ID: 1, OwnerID: userID, URL: "http://cdn.test/a.png", ContentType: "image/png"}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			var body bytes.Buffer
+			mw := multipart.NewWriter(&body)
+			part, err := mw.CreateFormFile(tt.field, "a.png")
+			require.NoError(t, err)
+			part.Write([]byte("fake-image-bytes"))
+			require.NoError(t, mw.Close())
+
+			req := httptest.NewRequest(http.MethodPost, "/images", &body)
+			req.Header.Set("Content-Type", mw.FormDataContentType())
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "userID", tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			h.UploadImage(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_ReplaceAdImages(t *testing.T) {
+	tests := []struct {
+		name        string
+		requestBody string
+		userID      interface{}
+		mockError   error
+		wantStatus  int
+	}{
+		{
+			name:        "successful reorder",
+			requestBody: `{"images":["http://example.com/2.jpg","http://example.com/1.jpg"]}`,
+			userID:      int64(1),
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "empty gallery",
+			requestBody: `{"images":[]}`,
+			userID:      int64(1),
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "not the author",
+			requestBody: `{"images":["http://example.com/1.jpg"]}`,
+			userID:      int64(2),
+			mockError:   model.ErrNotAdAuthor,
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "unauthorized",
+			requestBody: `{"images":["http://example.com/1.jpg"]}`,
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				ReplaceAdImagesFunc: func(ctx context.Context, adID int64, urls []string, userID int64) ([]model.AdImage, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					images := make([]model.AdImage, 0, len(urls))
+					for i, u := range urls {
+						images = append(images, model.AdImage{ID: int64(i + 1), AdID: adID, URL: u, Position: i})
+					}
+					return images, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodPut, "/ads/1/images", strings.NewReader(tt.requestBody))
+			req.SetPathValue("id", "1")
+			req.Header.Set("Content-Type", "application/json")
+			if tt.userID != nil {
+				req = req.WithContext(context.WithValue(req.Context(), "userID", tt.userID))
+			}
+			w := httptest.NewRecorder()
+
+			h.ReplaceAdImages(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			if tt.wantStatus == http.StatusOK {
+				var resp []ad.Image
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+				require.Len(t, resp, 2)
+				assert.Equal(t, "http://example.com/2.jpg", resp[0].URL)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteAdImage(t *testing.T) {
+	tests := []struct {
+		name       string
+		imageID    string
+		mockError  error
+		wantStatus int
+	}{
+		{
+			name:       "successful delete",
+			imageID:    "3",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid image id",
+			imageID:    "x",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "image not found",
+			imageID:    "9",
+			mockError:  model.ErrAdImageNotFound,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "last image",
+			imageID:    "3",
+			mockError:  model.ErrNoImages,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				DeleteAdImageFunc: func(ctx context.Context, adID, imageID int64, userID int64) ([]model.AdImage, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					return []model.AdImage{{ID: 4, AdID: adID, URL: "http://example.com/1.jpg"}}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodDelete, "/ads/1/images/"+tt.imageID, nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("imageID", tt.imageID)
+			req = req.WithContext(context.WithValue(req.Context(), "userID", int64(1)))
+			w := httptest.NewRecorder()
+
+			h.DeleteAdImage(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestHandler_UpdateExchangeRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		mockError  error
+		wantRate   string
+		wantStatus int
+	}{
+		{
+			name:       "numeric rate",
+			body:       `{"rate": 91.25}`,
+			wantRate:   "91.25",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "string rate",
+			body:       `{"rate": "0.0109"}`,
+			wantRate:   "0.0109",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing rate",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "not an admin",
+			body:       `{"rate": 91}`,
+			mockError:  model.ErrNotAdmin,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "base currency",
+			body:       `{"rate": 2}`,
+			mockError:  model.ErrBaseCurrencyRate,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRate string
+			mockSvc := &mockService{
+				UpdateRateFunc: func(ctx context.Context, currency, rate string, userID int64) (*model.ExchangeRate, error) {
+					if tt.mockError != nil {
+						return nil, tt.mockError
+					}
+					gotRate = rate
+					return &model.ExchangeRate{Currency: currency, Rate: rate, UpdatedAt: time.Now()}, nil
+				},
+			}
+
+			h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+			req := httptest.NewRequest(http.MethodPut, "/exchange-rates/USD", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("currency", "USD")
+			req = req.WithContext(context.WithValue(req.Context(), "userID", int64(1)))
+			w := httptest.NewRecorder()
+
+			h.UpdateExchangeRate(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantRate != "" {
+				assert.Equal(t, tt.wantRate, gotRate)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAds_DisplayCurrency(t *testing.T) {
+	mockSvc := &mockService{
+		ParseListRequestFunc: func(q url.Values) (ad.ListRequest, error) {
+			return ad.ListRequest{Page: 1, PageSize: 10, Currency: q.Get("currency"), Cursor: q.Get("cursor")}, nil
+		},
+		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+			if req.Currency == "JPY" {
+				return nil, model.ErrUnsupportedCurrency
+			}
+			if req.Cursor == "stale" {
+				return nil, model.ErrCursorMismatch
+			}
+			return &model.AdList{
+				Ads: []*model.AdWithAuthor{
+					{ID: 1, Price: 100, Currency: "USD", DisplayPrice: 9000, AuthorID: 5},
+				},
+				Total: 1,
+			}, nil
+		},
+	}
+
+	h := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc)
+
+	req := httptest.NewRequest(http.MethodGet, "/watch-ads?currency=RUB", nil)
+	w := httptest.NewRecorder()
+	h.GetAds(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"items":[{"id":1,"title":"","description":"","image_url":"","price":1.00,"currency":"USD","display_price":90.00,"author_login":"","author_rating":null,"is_owner":false,"is_favorite":false}],"page":1,"page_size":10,"total":1,"total_pages":1,"sort_by":"","sort_order":"","currency":"RUB"}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/watch-ads?currency=JPY", nil)
+	w = httptest.NewRecorder()
+	h.GetAds(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/watch-ads?cursor=stale", nil)
+	w = httptest.NewRecorder()
+	h.GetAds(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandler_ReloadContentPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokenRole  string
+		mockError  error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "admin reloads rules",
+			tokenRole:  model.RoleAdmin,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"rules":6}`,
+		},
+		{
+			name:       "moderator",
+			tokenRole:  model.RoleModerator,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "stale admin claim",
+			tokenRole:  model.RoleAdmin,
+			mockError:  model.ErrNotAdmin,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid rules file",
+			tokenRole:  model.RoleAdmin,
+			mockError:  errors.New("invalid content rule: duplicate rule \"email\""),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSvc := &mockService{
+				ReloadPolicyFunc: func(ctx context.Context, userID int64) (int, error) {
+					if tt.mockError != nil {
+						return 0, tt.mockError
+					}
+					return 6, nil
+				},
+			}
+
+			router := handler.New(mockSvc, jwtkeys.HMAC("secret").Keyfunc).Route()
+
+			signed, err := jwtkeys.HMAC("secret").Sign(jwt.MapClaims{
+				"sub":  1,
+				"role": tt.tokenRole,
+				"exp":  time.Now().Add(time.Hour).Unix(),
+			})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/content-policy/reload", nil)
+			req.Header.Set("Authorization", "Bearer "+signed)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
 }
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/image"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	maxUploadRequestBytes = 32 << 20
+	multipartMemory       = 8 << 20
+)
+
+func (h *Handler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		http.Error(w, "Content-Type must be multipart/form-data", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadRequestBytes)
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, model.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "Invalid multipart form", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, "Missing image file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	img, err := h.service.UploadImage(r.Context(), file, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrImageTooLarge):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.Is(err, model.ErrUnsupportedImageType):
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		case errors.Is(err, model.ErrImageDimensions):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Failed to upload image", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := image.UploadResponse{
+		ID:          img.ID,
+		URL:         img.URL,
+		ContentType: img.ContentType,
+		SizeBytes:   img.SizeBytes,
+		Width:       img.Width,
+		Height:      img.Height,
+		Thumbnail:   img.Variants.Thumbnail,
+		Medium:      img.Variants.Medium,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
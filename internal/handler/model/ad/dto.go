@@ -1,36 +1,147 @@
 package ad
 
+import (
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
 type CreateRequest struct {
-	Title       string  `json:"title" validate:"required,max=100"`
-	Description string  `json:"description" validate:"required,max=1000"`
-	ImageURL    string  `json:"image_url" validate:"required,url"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
+	Title       string       `json:"title" validate:"required,max=100"`
+	Description string       `json:"description" validate:"required,max=1000"`
+	ImageURL    string       `json:"image_url" validate:"required_without=Images,omitempty,url"`
+	Images      []string     `json:"images" validate:"omitempty,max=10,dive,required,url"`
+	Price       money.Amount `json:"price" validate:"required,gt=0"`
+	Currency    string       `json:"currency" validate:"omitempty,iso4217"`
+	CategoryID  int64        `json:"category_id" validate:"omitempty,gt=0"`
+}
+
+type ImagesRequest struct {
+	Images []string `json:"images" validate:"required,min=1,max=10,dive,required,url"`
+}
+
+type Image struct {
+	ID            int64          `json:"id"`
+	URL           string         `json:"url"`
+	Position      int            `json:"position"`
+	ImageVariants *ImageVariants `json:"image_variants,omitempty"`
+}
+
+type UpdateRequest struct {
+	Title       *string       `json:"title" validate:"omitnil,min=1,max=100"`
+	Description *string       `json:"description" validate:"omitnil,min=1,max=1000"`
+	ImageURL    *string       `json:"image_url" validate:"omitempty,url"`
+	Price       *money.Amount `json:"price" validate:"omitempty,gt=0"`
+	Currency    *string       `json:"currency" validate:"omitempty,iso4217"`
+	CategoryID  *int64        `json:"category_id" validate:"omitempty,gt=0"`
 }
 
 type Response struct {
-	ID          int64   `json:"id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	ImageURL    string  `json:"image_url"`
-	Price       float64 `json:"price"`
-	AuthorID    int64   `json:"author_id"`
+	ID              int64          `json:"id"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	ImageURL        string         `json:"image_url"`
+	ImageVariants   *ImageVariants `json:"image_variants,omitempty"`
+	Images          []Image        `json:"images,omitempty"`
+	Price           money.Amount   `json:"price"`
+	Currency        string         `json:"currency"`
+	AuthorID        int64          `json:"author_id"`
+	CategoryID      int64          `json:"category_id,omitempty"`
+	AuthorLogin     string         `json:"author_login,omitempty"`
+	Status          string         `json:"status"`
+	RejectionReason string         `json:"rejection_reason,omitempty"`
+	PolicyMatches   []string       `json:"policy_matches,omitempty"`
+	Hidden          bool           `json:"hidden,omitempty"`
+	IsOwner         bool           `json:"is_owner"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+type RejectRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ModerationPage struct {
+	Items      []Response `json:"items"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	Total      int64      `json:"total"`
+	TotalPages int        `json:"total_pages"`
+}
+
+type ImageVariants struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
 }
 
 type ListRequest struct {
-	Page      int     `json:"page" validate:"gte=1"`
-	PageSize  int     `json:"page_size" validate:"gte=1,lte=100"`
-	SortBy    string  `json:"sort_by" validate:"oneof=created_at price"`
-	SortOrder string  `json:"sort_order" validate:"oneof=asc desc"`
-	MinPrice  float64 `json:"min_price" validate:"gte=0"`
-	MaxPrice  float64 `json:"max_price" validate:"gte=0"`
+	Page            int          `json:"page" validate:"gte=1"`
+	PageSize        int          `json:"page_size" validate:"gte=1,lte=100"`
+	SortBy          string       `json:"sort_by" validate:"oneof=created_at price relevance"`
+	SortOrder       string       `json:"sort_order" validate:"oneof=asc desc"`
+	MinPrice        money.Amount `json:"min_price" validate:"gte=0"`
+	MaxPrice        money.Amount `json:"max_price" validate:"gte=0"`
+	Query           string       `json:"q" validate:"max=200"`
+	Category        int64        `json:"category" validate:"gte=0"`
+	MinAuthorRating float64      `json:"min_author_rating" validate:"omitempty,gte=1,lte=5"`
+	AuthorID        int64        `json:"-"`
+	Currency        string       `json:"currency"`
+	Cursor          string       `json:"cursor"`
+	After           *Cursor      `json:"-"`
+}
+
+type Cursor struct {
+	SortBy    string       `json:"s"`
+	SortOrder string       `json:"o"`
+	ID        int64        `json:"i"`
+	Price     money.Amount `json:"p,omitempty"`
+	CreatedAt time.Time    `json:"c,omitempty"`
+	Rank      float32      `json:"r,omitempty"`
+	Currency  string       `json:"cur,omitempty"`
+	Filters   string       `json:"f,omitempty"`
 }
 
 type ListResponse struct {
-	ID          int64   `json:"id"`
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	ImageURL    string  `json:"image_url"`
-	Price       float64 `json:"price"`
-	AuthorLogin string  `json:"author_login"`
-	IsOwner     bool    `json:"is_owner"`
+	ID            int64          `json:"id"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	ImageURL      string         `json:"image_url"`
+	ImageVariants *ImageVariants `json:"image_variants,omitempty"`
+	Images        []Image        `json:"images,omitempty"`
+	Price         money.Amount   `json:"price"`
+	Currency      string         `json:"currency"`
+	DisplayPrice  money.Amount   `json:"display_price"`
+	CategoryID    int64          `json:"category_id,omitempty"`
+	AuthorLogin   string         `json:"author_login"`
+	AuthorRating  *float64       `json:"author_rating"`
+	IsOwner       bool           `json:"is_owner"`
+	IsFavorite    bool           `json:"is_favorite"`
+}
+
+type ListPage struct {
+	Items      []ListResponse `json:"items"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	Total      int64          `json:"total"`
+	TotalPages int            `json:"total_pages"`
+	SortBy     string         `json:"sort_by"`
+	SortOrder  string         `json:"sort_order"`
+	Currency   string         `json:"currency"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+type FavoritesPage struct {
+	Items      []ListResponse `json:"items"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	Total      int64          `json:"total"`
+	TotalPages int            `json:"total_pages"`
+	Currency   string         `json:"currency"`
+}
+
+type FieldError struct {
+	Error  string `json:"error"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Rule   string `json:"rule,omitempty"`
 }
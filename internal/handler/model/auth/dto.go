@@ -3,9 +3,12 @@ package auth
 type RegistrationRequest struct {
 	Login    string `json:"login" validate:"required,min=4"`
 	Password string `json:"password" validate:"required,min=6"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email,max=254"`
 }
 
 type RegistrationResponse struct {
-	ID    int64  `json:"id"`
-	Login string `json:"login"`
+	ID                    int64  `json:"id"`
+	Login                 string `json:"login"`
+	Email                 string `json:"email,omitempty"`
+	EmailVerificationSent *bool  `json:"email_verification_sent,omitempty"`
 }
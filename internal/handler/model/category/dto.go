@@ -0,0 +1,8 @@
+package category
+
+type Node struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Children []Node `json:"children"`
+}
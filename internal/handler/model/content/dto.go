@@ -0,0 +1,5 @@
+package content
+
+type ReloadResponse struct {
+	Rules int `json:"rules"`
+}
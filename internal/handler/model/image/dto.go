@@ -0,0 +1,12 @@
+package image
+
+type UploadResponse struct {
+	ID          int64  `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Thumbnail   string `json:"thumbnail_url"`
+	Medium      string `json:"medium_url"`
+}
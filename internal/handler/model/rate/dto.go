@@ -0,0 +1,16 @@
+package rate
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type UpdateRequest struct {
+	Rate json.Number `json:"rate" validate:"required"`
+}
+
+type Response struct {
+	Currency  string    `json:"currency"`
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package report
+
+import (
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
+)
+
+type CreateRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=scam prohibited spam offensive wrong_category other"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+type Response struct {
+	ID         int64      `json:"id"`
+	AdID       int64      `json:"ad_id"`
+	ReporterID int64      `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Comment    string     `json:"comment,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ClosedAt   *time.Time `json:"closed_at,omitempty"`
+}
+
+type ReportedAd struct {
+	Ad             ad.Response `json:"ad"`
+	OpenReports    int64       `json:"open_reports"`
+	Reasons        []string    `json:"reasons"`
+	LastReportedAt time.Time   `json:"last_reported_at"`
+}
+
+type Page struct {
+	Items      []ReportedAd `json:"items"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
+	Total      int64        `json:"total"`
+	TotalPages int          `json:"total_pages"`
+}
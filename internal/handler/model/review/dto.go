@@ -0,0 +1,31 @@
+package review
+
+import "time"
+
+type CreateRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Text   string `json:"text" validate:"max=1000"`
+}
+
+type Reviewer struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type Response struct {
+	ID        int64     `json:"id"`
+	AdID      int64     `json:"ad_id,omitempty"`
+	AdTitle   string    `json:"ad_title"`
+	Reviewer  Reviewer  `json:"reviewer"`
+	Rating    int       `json:"rating"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Page struct {
+	Items      []Response `json:"items"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	Total      int64      `json:"total"`
+	TotalPages int        `json:"total_pages"`
+}
@@ -0,0 +1,50 @@
+package thread
+
+import "time"
+
+type CreateRequest struct {
+	Message string `json:"message" validate:"required,max=2000"`
+}
+
+type MessageRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type Participant struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+type MessageResponse struct {
+	ID        int64     `json:"id"`
+	ThreadID  int64     `json:"thread_id"`
+	SenderID  int64     `json:"sender_id"`
+	Body      string    `json:"body"`
+	IsMine    bool      `json:"is_mine"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Response struct {
+	ID            int64            `json:"id"`
+	AdID          int64            `json:"ad_id"`
+	AdTitle       string           `json:"ad_title"`
+	Buyer         Participant      `json:"buyer"`
+	Seller        Participant      `json:"seller"`
+	LastMessage   *MessageResponse `json:"last_message,omitempty"`
+	Unread        int64            `json:"unread"`
+	CreatedAt     time.Time        `json:"created_at"`
+	LastMessageAt time.Time        `json:"last_message_at"`
+}
+
+type Page struct {
+	Items      []Response `json:"items"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	Total      int64      `json:"total"`
+	TotalPages int        `json:"total_pages"`
+	Unread     int64      `json:"unread"`
+}
+
+type Messages struct {
+	Items []MessageResponse `json:"items"`
+}
@@ -0,0 +1,48 @@
+package user
+
+import "time"
+
+type RoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+type Response struct {
+	ID        int64     `json:"id"`
+	Login     string    `json:"login"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Profile struct {
+	ID           int64     `json:"id"`
+	Login        string    `json:"login"`
+	DisplayName  string    `json:"display_name"`
+	About        string    `json:"about"`
+	AvatarURL    string    `json:"avatar_url"`
+	Location     string    `json:"location"`
+	Rating       *float64  `json:"rating"`
+	ReviewsCount int64     `json:"reviews_count"`
+	MemberSince  time.Time `json:"member_since"`
+}
+
+type Me struct {
+	Profile
+	Role          string `json:"role"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name" validate:"omitempty,max=50"`
+	About       *string `json:"about" validate:"omitempty,max=1000"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,max=2048"`
+	Location    *string `json:"location" validate:"omitempty,max=100"`
+}
+
+type DeleteRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email,max=254"`
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	defaultQueuePageSize = 20
+	maxQueuePageSize     = 100
+)
+
+func (h *Handler) GetModerationQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r.URL.Query(), defaultQueuePageSize, maxQueuePageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.GetModerationQueue(r.Context(), page, pageSize, userID)
+	if err != nil {
+		writeAdError(w, err, "Failed to fetch moderation queue")
+		return
+	}
+
+	resp := ad.ModerationPage{
+		Items:      make([]ad.Response, 0, len(list.Ads)),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      list.Total,
+		TotalPages: totalPages(list.Total, pageSize),
+	}
+	for _, adItem := range list.Ads {
+		resp.Items = append(resp.Items, toAdWithAuthorResponse(adItem, userID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) ApproveAd(w http.ResponseWriter, r *http.Request) {
+	h.changeAdStatus(w, r, func(id, userID int64) (*model.AdWithAuthor, error) {
+		return h.service.ApproveAd(r.Context(), id, userID)
+	})
+}
+
+func (h *Handler) ArchiveAd(w http.ResponseWriter, r *http.Request) {
+	h.changeAdStatus(w, r, func(id, userID int64) (*model.AdWithAuthor, error) {
+		return h.service.ArchiveAd(r.Context(), id, userID)
+	})
+}
+
+func (h *Handler) RejectAd(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	var req ad.RejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.changeAdStatus(w, r, func(id, userID int64) (*model.AdWithAuthor, error) {
+		return h.service.RejectAd(r.Context(), id, req.Reason, userID)
+	})
+}
+
+func (h *Handler) changeAdStatus(w http.ResponseWriter, r *http.Request, change func(id, userID int64) (*model.AdWithAuthor, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := change(id, userID)
+	if err != nil {
+		writeAdError(w, err, "Failed to change ad status")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toAdWithAuthorResponse(updated, userID))
+}
+
+func parsePagination(q url.Values, defaultSize, maxSize int) (int, int, error) {
+	page, pageSize := 1, defaultSize
+
+	if val := q.Get("page"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errors.New("invalid page value")
+		}
+		page = parsed
+	}
+
+	if val := q.Get("page_size"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 || parsed > maxSize {
+			return 0, 0, errors.New("invalid page_size value")
+		}
+		pageSize = parsed
+	}
+
+	return page, pageSize, nil
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	profile, err := h.service.GetProfile(r.Context(), r.PathValue("login"))
+	if err != nil {
+		writeProfileError(w, err, "Failed to fetch profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toProfileResponse(profile))
+}
+
+func (h *Handler) GetUserAds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req, err := h.service.ParseListRequest(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID, _ := userIDFromContext(r)
+
+	list, err := h.service.GetUserAds(r.Context(), r.PathValue("login"), &req, userID)
+	if err != nil {
+		writeProfileError(w, err, "Failed to fetch ads")
+		return
+	}
+
+	writeAdList(w, req, list, userID)
+}
+
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	profile, err := h.service.GetMe(r.Context(), userID)
+	if err != nil {
+		writeProfileError(w, err, "Failed to fetch profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toMeResponse(profile))
+}
+
+func (h *Handler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Only PATCH method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req user.UpdateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(r.Context(), req, userID)
+	if err != nil {
+		writeProfileError(w, err, "Failed to update profile")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(toMeResponse(profile))
+}
+
+func toProfileResponse(p *model.Profile) user.Profile {
+	return user.Profile{
+		ID:           p.ID,
+		Login:        p.Login,
+		DisplayName:  p.DisplayName,
+		About:        p.About,
+		AvatarURL:    p.AvatarURL,
+		Location:     p.Location,
+		Rating:       p.Rating,
+		ReviewsCount: p.ReviewsCount,
+		MemberSince:  p.CreatedAt,
+	}
+}
+
+func toMeResponse(p *model.Profile) user.Me {
+	return user.Me{
+		Profile:       toProfileResponse(p),
+		Role:          p.Role,
+		Email:         p.Email,
+		EmailVerified: p.EmailVerifiedAt != nil,
+	}
+}
+
+func writeProfileError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeAdError(w, err, fallback)
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/report"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) ReportAd(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req report.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.ReportAd(r.Context(), id, req.Reason, req.Comment, userID)
+	if err != nil {
+		writeReportError(w, err, "Failed to report ad")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toReportResponse(created))
+}
+
+func (h *Handler) GetAdReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reports, err := h.service.GetAdReports(r.Context(), id, userID)
+	if err != nil {
+		writeReportError(w, err, "Failed to fetch reports")
+		return
+	}
+
+	resp := make([]report.Response, 0, len(reports))
+	for i := range reports {
+		resp = append(resp, toReportResponse(&reports[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) DismissReports(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DismissReports(r.Context(), id, userID); err != nil {
+		writeReportError(w, err, "Failed to dismiss reports")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetReportedAds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r.URL.Query(), defaultQueuePageSize, maxQueuePageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.GetReportedAds(r.Context(), page, pageSize, userID)
+	if err != nil {
+		writeReportError(w, err, "Failed to fetch reported ads")
+		return
+	}
+
+	resp := report.Page{
+		Items:      make([]report.ReportedAd, 0, len(list.Items)),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      list.Total,
+		TotalPages: totalPages(list.Total, pageSize),
+	}
+	for _, item := range list.Items {
+		resp.Items = append(resp.Items, report.ReportedAd{
+			Ad:             toAdWithAuthorResponse(item.Ad, userID),
+			OpenReports:    item.OpenReports,
+			Reasons:        item.Reasons,
+			LastReportedAt: item.LastReportedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func writeReportError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrDuplicateReport):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, model.ErrReportOwnAd):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeAdError(w, err, fallback)
+	}
+}
+
+func toReportResponse(r *model.Report) report.Response {
+	return report.Response{
+		ID:         r.ID,
+		AdID:       r.AdID,
+		ReporterID: r.ReporterID,
+		Reason:     r.Reason,
+		Comment:    r.Comment,
+		Status:     r.Status,
+		CreatedAt:  r.CreatedAt,
+		ClosedAt:   r.ClosedAt,
+	}
+}
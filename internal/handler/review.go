@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/review"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	defaultReviewsPageSize = 20
+	maxReviewsPageSize     = 100
+)
+
+func (h *Handler) CreateReview(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req review.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.service.CreateReview(r.Context(), id, req.Rating, req.Text, userID)
+	if err != nil {
+		writeReviewError(w, err, "Failed to create review")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toReviewResponse(*created))
+}
+
+func (h *Handler) GetSellerReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r.URL.Query(), defaultReviewsPageSize, maxReviewsPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.GetSellerReviews(r.Context(), r.PathValue("login"), page, pageSize)
+	if err != nil {
+		writeReviewError(w, err, "Failed to fetch reviews")
+		return
+	}
+
+	resp := review.Page{
+		Items:      make([]review.Response, 0, len(list.Items)),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      list.Total,
+		TotalPages: totalPages(list.Total, pageSize),
+	}
+	for _, item := range list.Items {
+		resp.Items = append(resp.Items, toReviewResponse(item))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func toReviewResponse(r model.Review) review.Response {
+	return review.Response{
+		ID:      r.ID,
+		AdID:    r.AdID,
+		AdTitle: r.AdTitle,
+		Reviewer: review.Reviewer{
+			ID:    r.ReviewerID,
+			Login: r.ReviewerLogin,
+		},
+		Rating:    r.Rating,
+		Text:      r.Text,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+func writeReviewError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrDuplicateReview):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, model.ErrReviewNotAllowed):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, model.ErrReviewOwnAd):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeProfileError(w, err, fallback)
+	}
+}
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/thread"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	defaultThreadsPageSize = 20
+	maxThreadsPageSize     = 100
+	defaultMessagesLimit   = 50
+	maxMessagesLimit       = 200
+)
+
+func (h *Handler) StartThread(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseAdID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req thread.CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	started, created, err := h.service.StartThread(r.Context(), id, req.Message, userID)
+	if err != nil {
+		writeThreadError(w, err, "Failed to start thread")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(toThreadResponse(started, userID))
+}
+
+func (h *Handler) GetThreads(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	page, pageSize, err := parsePagination(r.URL.Query(), defaultThreadsPageSize, maxThreadsPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.service.GetThreads(r.Context(), page, pageSize, userID)
+	if err != nil {
+		writeThreadError(w, err, "Failed to fetch threads")
+		return
+	}
+
+	resp := thread.Page{
+		Items:      make([]thread.Response, 0, len(list.Items)),
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      list.Total,
+		TotalPages: totalPages(list.Total, pageSize),
+		Unread:     list.Unread,
+	}
+	for _, item := range list.Items {
+		resp.Items = append(resp.Items, toThreadResponse(item, userID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) GetThreadMessages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseThreadID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	q := r.URL.Query()
+	var afterID int64
+	if val := q.Get("after_id"); val != "" {
+		afterID, err = strconv.ParseInt(val, 10, 64)
+		if err != nil || afterID < 0 {
+			http.Error(w, "invalid after_id value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := defaultMessagesLimit
+	if val := q.Get("limit"); val != "" {
+		limit, err = strconv.Atoi(val)
+		if err != nil || limit < 1 || limit > maxMessagesLimit {
+			http.Error(w, "invalid limit value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	messages, err := h.service.GetThreadMessages(r.Context(), id, afterID, limit, userID)
+	if err != nil {
+		writeThreadError(w, err, "Failed to fetch messages")
+		return
+	}
+
+	resp := thread.Messages{Items: make([]thread.MessageResponse, 0, len(messages))}
+	for i := range messages {
+		resp.Items = append(resp.Items, toMessageResponse(&messages[i], userID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (h *Handler) PostMessage(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseThreadID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req thread.MessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	msg, err := h.service.PostMessage(r.Context(), id, req.Body, userID)
+	if err != nil {
+		writeThreadError(w, err, "Failed to post message")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(toMessageResponse(msg, userID))
+}
+
+func (h *Handler) MarkThreadRead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := parseThreadID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.MarkThreadRead(r.Context(), id, userID); err != nil {
+		writeThreadError(w, err, "Failed to mark thread as read")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseThreadID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid thread id")
+	}
+	return id, nil
+}
+
+func writeThreadError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, model.ErrThreadNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, model.ErrNotThreadParticipant):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, model.ErrMessageOwnAd):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeAdError(w, err, fallback)
+	}
+}
+
+func toThreadResponse(t *model.Thread, userID int64) thread.Response {
+	resp := thread.Response{
+		ID:            t.ID,
+		AdID:          t.AdID,
+		AdTitle:       t.AdTitle,
+		Buyer:         thread.Participant{ID: t.BuyerID, Login: t.BuyerLogin},
+		Seller:        thread.Participant{ID: t.SellerID, Login: t.SellerLogin},
+		Unread:        t.Unread,
+		CreatedAt:     t.CreatedAt,
+		LastMessageAt: t.LastMessageAt,
+	}
+	if t.LastMessage != nil {
+		msg := toMessageResponse(t.LastMessage, userID)
+		resp.LastMessage = &msg
+	}
+	return resp
+}
+
+func toMessageResponse(m *model.Message, userID int64) thread.MessageResponse {
+	return thread.MessageResponse{
+		ID:        m.ID,
+		ThreadID:  m.ThreadID,
+		SenderID:  m.SenderID,
+		Body:      m.Body,
+		IsMine:    m.SenderID == userID,
+		CreatedAt: m.CreatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (h *Handler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if r.Method != http.MethodPut {
+		http.Error(w, "Only PUT method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := userIDFromContext(r)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	targetID, err := parseUserID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req user.RoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		http.Error(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.service.SetUserRole(r.Context(), targetID, req.Role, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrNotAdmin):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, model.ErrUserNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, model.ErrInvalidRole):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, model.ErrRoleLocked):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, context.Canceled):
+			http.Error(w, "Request Timeout", http.StatusRequestTimeout)
+		default:
+			http.Error(w, "Failed to update role", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.Response{
+		ID:        updated.ID,
+		Login:     updated.Login,
+		Role:      updated.Role,
+		CreatedAt: updated.CreatedAt,
+	})
+}
+
+func parseUserID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid user id")
+	}
+	return id, nil
+}
@@ -0,0 +1,63 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name         string
+		width        int
+		height       int
+		maxW, maxH   int
+		wantW, wantH int
+	}{
+		{"landscape", 1000, 500, 200, 200, 200, 100},
+		{"portrait", 300, 900, 200, 200, 66, 200},
+		{"already fits", 50, 40, 200, 200, 50, 40},
+		{"thin strip", 4000, 1, 200, 200, 200, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			got := Fit(src, tt.maxW, tt.maxH)
+			if got.Bounds().Dx() != tt.wantW || got.Bounds().Dy() != tt.wantH {
+				t.Errorf("expected %dx%d, got %dx%d", tt.wantW, tt.wantH, got.Bounds().Dx(), got.Bounds().Dy())
+			}
+		})
+	}
+}
+
+func TestFitAveragesColors(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	src.Set(1, 0, color.NRGBA{B: 255, A: 255})
+
+	got := Fit(src, 1, 1).NRGBAAt(0, 0)
+	if got.R != 127 || got.B != 127 || got.A != 255 {
+		t.Errorf("expected averaged pixel, got %+v", got)
+	}
+}
+
+func TestEncodeJPEG(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	var buf bytes.Buffer
+	if err := EncodeJPEG(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, g, b, _ := decoded.At(1, 1).RGBA()
+	if r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("expected transparent pixels to be flattened to white, got %d %d %d", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,51 @@
+package jwtkeys
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrUnsupportedAlg   = errors.New("unsupported signing algorithm")
+	ErrNoActiveKey      = errors.New("no active signing key")
+	ErrUnexpectedMethod = errors.New("unexpected signing method")
+)
+
+type Signer interface {
+	Sign(claims jwt.Claims) (string, error)
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type HMAC []byte
+
+func (h HMAC) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h))
+}
+
+func (h HMAC) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return []byte(h), nil
+}
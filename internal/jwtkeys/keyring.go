@@ -0,0 +1,360 @@
+package jwtkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	kidTimeLayout  = "20060102T150405Z"
+	rsaKeyBits     = 2048
+	checkInterval  = time.Minute
+	reloadCooldown = 10 * time.Second
+
+	defaultRotation = 30 * 24 * time.Hour
+	defaultTokenTTL = 15 * time.Minute
+)
+
+type key struct {
+	kid     string
+	alg     string
+	private crypto.Signer
+	public  crypto.PublicKey
+	created time.Time
+}
+
+type Keyring struct {
+	mu         sync.RWMutex
+	dir        string
+	alg        string
+	keys       map[string]*key
+	active     *key
+	lastReload time.Time
+	now        func() time.Time
+}
+
+func NewKeyring(dir, alg string) (*Keyring, error) {
+	if alg != AlgRS256 && alg != AlgEdDSA {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlg, alg)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{
+		dir:  dir,
+		alg:  alg,
+		keys: make(map[string]*key),
+		now:  time.Now,
+	}
+	if err := k.Load(); err != nil {
+		return nil, err
+	}
+	if k.active == nil {
+		if err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+func (k *Keyring) Load() error {
+	paths, err := filepath.Glob(filepath.Join(k.dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*key, len(paths))
+	for _, path := range paths {
+		loaded, err := loadKey(path)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", filepath.Base(path), err)
+		}
+		keys[loaded.kid] = loaded
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys = keys
+	k.active = newest(keys)
+	k.lastReload = k.now()
+	return nil
+}
+
+func (k *Keyring) Rotate() error {
+	now := k.now().UTC()
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	kid := now.Format(kidTimeLayout) + "-" + hex.EncodeToString(suffix)
+
+	private, err := generateKey(k.alg)
+	if err != nil {
+		return err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+	if err := writeKeyFile(k.dir, kid, der); err != nil {
+		return err
+	}
+
+	created := &key{
+		kid:     kid,
+		alg:     k.alg,
+		private: private,
+		public:  private.Public(),
+		created: now,
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[kid] = created
+	k.active = created
+	return nil
+}
+
+func (k *Keyring) Prune(tokenTTL time.Duration) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	ordered := sortedKeys(k.keys)
+	now := k.now()
+	for i := 0; i < len(ordered)-1; i++ {
+		superseded := ordered[i+1].created
+		if now.Sub(superseded) <= tokenTTL {
+			continue
+		}
+		if err := os.Remove(filepath.Join(k.dir, ordered[i].kid+".pem")); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		delete(k.keys, ordered[i].kid)
+	}
+	return nil
+}
+
+func (k *Keyring) Run(ctx context.Context, rotation, tokenTTL time.Duration) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := k.tick(rotation, tokenTTL); err != nil {
+				log.Printf("signing key rotation: %v", err)
+			}
+		}
+	}
+}
+
+func (k *Keyring) tick(rotation, tokenTTL time.Duration) error {
+	if rotation <= 0 {
+		rotation = defaultRotation
+	}
+	if tokenTTL <= 0 {
+		tokenTTL = defaultTokenTTL
+	}
+
+	if err := k.Load(); err != nil {
+		return err
+	}
+
+	k.mu.RLock()
+	due := k.active == nil || k.now().Sub(k.active.created) >= rotation
+	k.mu.RUnlock()
+
+	if due {
+		if err := k.Rotate(); err != nil {
+			return err
+		}
+	}
+	return k.Prune(tokenTTL)
+}
+
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	active := k.active
+	k.mu.RUnlock()
+
+	if active == nil {
+		return "", ErrNoActiveKey
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(active.alg), claims)
+	token.Header["kid"] = active.kid
+	return token.SignedString(active.private)
+}
+
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownKey
+	}
+
+	found := k.lookup(kid)
+	if found == nil && k.reloadAllowed() {
+		if err := k.Load(); err != nil {
+			return nil, err
+		}
+		found = k.lookup(kid)
+	}
+	if found == nil {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != found.alg {
+		return nil, ErrUnexpectedMethod
+	}
+	return found.public, nil
+}
+
+func (k *Keyring) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+	for _, key := range sortedKeys(k.keys) {
+		jwk := JWK{Kid: key.kid, Alg: key.alg, Use: "sig"}
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+func (k *Keyring) lookup(kid string) *key {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[kid]
+}
+
+func (k *Keyring) reloadAllowed() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.now().Sub(k.lastReload) >= reloadCooldown
+}
+
+func loadKey(path string) (*key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("expected a PKCS#8 PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &key{kid: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		loaded.alg = AlgRS256
+		loaded.private = private
+	case ed25519.PrivateKey:
+		loaded.alg = AlgEdDSA
+		loaded.private = private
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+	loaded.public = loaded.private.Public()
+
+	stamp, _, _ := strings.Cut(loaded.kid, "-")
+	if created, err := time.Parse(kidTimeLayout, stamp); err == nil {
+		loaded.created = created
+	} else {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		loaded.created = info.ModTime()
+	}
+
+	return loaded, nil
+}
+
+func generateKey(alg string) (crypto.Signer, error) {
+	switch alg {
+	case AlgRS256:
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+}
+
+func writeKeyFile(dir, kid string, der []byte) error {
+	tmp, err := os.CreateTemp(dir, ".key-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := pem.Encode(tmp, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, kid+".pem"))
+}
+
+func sortedKeys(keys map[string]*key) []*key {
+	ordered := make([]*key, 0, len(keys))
+	for _, k := range keys {
+		ordered = append(ordered, k)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].created.Equal(ordered[j].created) {
+			return ordered[i].kid < ordered[j].kid
+		}
+		return ordered[i].created.Before(ordered[j].created)
+	})
+	return ordered
+}
+
+func newest(keys map[string]*key) *key {
+	ordered := sortedKeys(keys)
+	if len(ordered) == 0 {
+		return nil
+	}
+	return ordered[len(ordered)-1]
+}
@@ -0,0 +1,121 @@
+package jwtkeys
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_SignAndVerify(t *testing.T) {
+	for _, alg := range []string{AlgEdDSA, AlgRS256} {
+		t.Run(alg, func(t *testing.T) {
+			ring, err := NewKeyring(t.TempDir(), alg)
+			require.NoError(t, err)
+
+			signed, err := ring.Sign(jwt.MapClaims{"sub": 1})
+			require.NoError(t, err)
+
+			token, err := jwt.Parse(signed, ring.Keyfunc)
+			require.NoError(t, err)
+			assert.Equal(t, alg, token.Method.Alg())
+			assert.NotEmpty(t, token.Header["kid"])
+
+			set := ring.JWKS()
+			require.Len(t, set.Keys, 1)
+			assert.Equal(t, token.Header["kid"], set.Keys[0].Kid)
+			assert.Equal(t, alg, set.Keys[0].Alg)
+		})
+	}
+}
+
+func TestKeyring_RejectsForeignTokens(t *testing.T) {
+	ring, err := NewKeyring(t.TempDir(), AlgEdDSA)
+	require.NoError(t, err)
+
+	hmacToken, err := HMAC("secret").Sign(jwt.MapClaims{"sub": 1})
+	require.NoError(t, err)
+	_, err = jwt.Parse(hmacToken, ring.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	other, err := NewKeyring(t.TempDir(), AlgEdDSA)
+	require.NoError(t, err)
+	foreign, err := other.Sign(jwt.MapClaims{"sub": 1})
+	require.NoError(t, err)
+	_, err = jwt.Parse(foreign, ring.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeyring_RotationAndPrune(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+
+	ring, err := NewKeyring(dir, AlgEdDSA)
+	require.NoError(t, err)
+	ring.now = func() time.Time { return now }
+
+	before, err := ring.Sign(jwt.MapClaims{"sub": 1})
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	require.NoError(t, ring.tick(time.Hour, 15*time.Minute))
+	assert.Len(t, ring.JWKS().Keys, 2)
+
+	after, err := ring.Sign(jwt.MapClaims{"sub": 1})
+	require.NoError(t, err)
+	_, err = jwt.Parse(before, ring.Keyfunc)
+	assert.NoError(t, err, "old key still verifies until it is pruned")
+
+	reloaded, err := NewKeyring(dir, AlgEdDSA)
+	require.NoError(t, err)
+	_, err = jwt.Parse(after, reloaded.Keyfunc)
+	assert.NoError(t, err, "other instances pick up rotated keys from the directory")
+
+	now = now.Add(30 * time.Minute)
+	require.NoError(t, ring.tick(time.Hour, 15*time.Minute))
+	assert.Len(t, ring.JWKS().Keys, 1)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.pem"))
+	assert.Len(t, files, 1)
+
+	_, err = jwt.Parse(before, ring.Keyfunc)
+	assert.Error(t, err)
+}
+
+func TestKeyring_TickDefaults(t *testing.T) {
+	now := time.Now()
+
+	ring, err := NewKeyring(t.TempDir(), AlgEdDSA)
+	require.NoError(t, err)
+	ring.now = func() time.Time { return now }
+
+	before, err := ring.Sign(jwt.MapClaims{"sub": 1})
+	require.NoError(t, err)
+
+	now = now.Add(checkInterval)
+	require.NoError(t, ring.tick(0, 0))
+	assert.Len(t, ring.JWKS().Keys, 1, "unset rotation does not rotate on every tick")
+
+	require.NoError(t, ring.Rotate())
+	now = now.Add(checkInterval)
+	require.NoError(t, ring.tick(0, 0))
+	assert.Len(t, ring.JWKS().Keys, 2, "unset token TTL keeps the previous key")
+
+	_, err = jwt.Parse(before, ring.Keyfunc)
+	assert.NoError(t, err)
+}
+
+func TestNewKeyring_InvalidKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("nope"), 0o600))
+
+	_, err := NewKeyring(dir, AlgEdDSA)
+	assert.Error(t, err)
+
+	_, err = NewKeyring(t.TempDir(), "HS256")
+	assert.ErrorIs(t, err, ErrUnsupportedAlg)
+}
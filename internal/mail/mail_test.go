@@ -0,0 +1,141 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"mime"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxMailer(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "var", "outbox.jsonl")
+
+	m, err := NewOutboxMailer(path)
+	require.NoError(t, err)
+
+	sentAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, m.Send(ctx, Message{To: "petr@example.com", Subject: "first", Body: "a", SentAt: sentAt}))
+	require.NoError(t, m.Send(ctx, Message{To: "pavel@example.com", Subject: "second", Body: "b"}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		got = append(got, msg)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, got, 2)
+	assert.Equal(t, Message{To: "petr@example.com", Subject: "first", Body: "a", SentAt: sentAt}, got[0])
+	assert.Equal(t, "pavel@example.com", got[1].To)
+	assert.False(t, got[1].SentAt.IsZero(), "missing send time is filled in")
+}
+
+func TestSMTPMailer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan []string, 1)
+	go serveSMTP(ln, received)
+
+	host, port, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	p, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	m := NewSMTPMailer(host, p, "", "", "market@example.com")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = m.Send(ctx, Message{
+		To:      "petr@example.com",
+		Subject: "Подтвердите email",
+		Body:    "line one\nline two",
+		SentAt:  time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	var commands []string
+	select {
+	case commands = <-received:
+	case <-ctx.Done():
+		t.Fatal("smtp server did not receive the message")
+	}
+
+	assert.Contains(t, commands, "MAIL FROM:<market@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<petr@example.com>")
+	assert.Contains(t, commands, "To: petr@example.com")
+	assert.Contains(t, commands, "Subject: "+mime.QEncoding.Encode("utf-8", "Подтвердите email"))
+	assert.Contains(t, commands, "Date: Sat, 17 Oct 2026 12:00:00 +0000")
+	assert.Contains(t, commands, "line two")
+}
+
+func TestSMTPMailer_RejectsHeaderInjection(t *testing.T) {
+	m := NewSMTPMailer("127.0.0.1", 25, "", "", "market@example.com")
+
+	err := m.Send(context.Background(), Message{To: "petr@example.com\r\nBcc: all@example.com", Subject: "hi"})
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func serveSMTP(ln net.Listener, received chan<- []string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var lines []string
+	inData := false
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+
+		if inData {
+			if line == "." {
+				inData = false
+				reply("250 queued")
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "EHLO"):
+			reply("250 localhost")
+		case line == "DATA":
+			inData = true
+			reply("354 go ahead")
+		case line == "QUIT":
+			reply("221 bye")
+			received <- lines
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
@@ -2,13 +2,19 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"math"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/AugustSerenity/marketplace/internal/revocation"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
+var errTokenRevoked = errors.New("token has been revoked")
+
+func AuthMiddleware(keyFunc jwt.Keyfunc, revoked revocation.Store) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString := r.Header.Get("Authorization")
@@ -24,12 +30,7 @@ func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 			}
 			tokenString = parts[1]
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(secretKey), nil
-			})
+			token, err := jwt.Parse(tokenString, keyFunc)
 
 			if err != nil || !token.Valid {
 				http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
@@ -48,13 +49,24 @@ func AuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "userID", int64(userID))
+			ctx, err := checkRevoked(r.Context(), revoked, claims, int64(userID))
+			if err != nil {
+				if errors.Is(err, errTokenRevoked) {
+					http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "Failed to verify token", http.StatusInternalServerError)
+				return
+			}
+
+			ctx = context.WithValue(ctx, "userID", int64(userID))
+			ctx = context.WithValue(ctx, "role", roleClaim(claims))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-func OptionalAuthMiddleware(secretKey string) func(http.Handler) http.Handler {
+func OptionalAuthMiddleware(keyFunc jwt.Keyfunc, revoked revocation.Store) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			auth := r.Header.Get("Authorization")
@@ -70,12 +82,7 @@ func OptionalAuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 			}
 
 			tokenStr := parts[1]
-			token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, jwt.ErrSignatureInvalid
-				}
-				return []byte(secretKey), nil
-			})
+			token, err := jwt.Parse(tokenStr, keyFunc)
 
 			if err != nil || !token.Valid {
 				next.ServeHTTP(w, r)
@@ -94,8 +101,50 @@ func OptionalAuthMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), "userID", int64(userID))
+			ctx, err := checkRevoked(r.Context(), revoked, claims, int64(userID))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx = context.WithValue(ctx, "userID", int64(userID))
+			ctx = context.WithValue(ctx, "role", roleClaim(claims))
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+func checkRevoked(ctx context.Context, revoked revocation.Store, claims jwt.MapClaims, userID int64) (context.Context, error) {
+	jti, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	iat, _ := claims["iat"].(float64)
+
+	if revoked != nil {
+		if jti == "" {
+			return ctx, errTokenRevoked
+		}
+		isRevoked, err := revoked.IsRevoked(ctx, jti, userID, numericTime(iat))
+		if err != nil {
+			return ctx, err
+		}
+		if isRevoked {
+			return ctx, errTokenRevoked
+		}
+	}
+
+	ctx = context.WithValue(ctx, "tokenID", jti)
+	ctx = context.WithValue(ctx, "tokenExpiresAt", numericTime(exp))
+	return ctx, nil
+}
+
+func roleClaim(claims jwt.MapClaims) string {
+	role, _ := claims["role"].(string)
+	if role == "" {
+		return defaultRole
+	}
+	return role
+}
+
+func numericTime(v float64) time.Time {
+	return time.UnixMilli(int64(math.Round(v * 1000)))
+}
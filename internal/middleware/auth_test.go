@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -69,7 +72,7 @@ func TestAuthMiddleware(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	authHandler := AuthMiddleware(secretKey)(handler)
+	authHandler := AuthMiddleware(jwtkeys.HMAC(secretKey).Keyfunc, nil)(handler)
 
 	tests := []struct {
 		name       string
@@ -153,7 +156,7 @@ func TestOptionalAuthMiddleware(t *testing.T) {
 			}
 
 			w := httptest.NewRecorder()
-			OptionalAuthMiddleware(secretKey)(handler).ServeHTTP(w, req)
+			OptionalAuthMiddleware(jwtkeys.HMAC(secretKey).Keyfunc, nil)(handler).ServeHTTP(w, req)
 
 			if w.Code != tt.expectStatus {
 				t.Errorf("expected status %d, got %d", tt.expectStatus, w.Code)
@@ -161,3 +164,75 @@ func TestOptionalAuthMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func generateTokenWithID(secretKey, jti string, issuedAt time.Time) string {
+	claims := jwt.MapClaims{
+		"sub": 12345,
+		"jti": jti,
+		"iat": float64(issuedAt.UnixMilli()) / 1000,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, _ := token.SignedString([]byte(secretKey))
+	return tokenString
+}
+
+func TestAuthMiddleware_Revocation(t *testing.T) {
+	secretKey := "mysecret"
+	ctx := context.Background()
+	store := revocation.NewMemoryStore(time.Hour)
+
+	issued := time.Now().Add(-time.Minute)
+	revokedToken := generateTokenWithID(secretKey, "revoked", issued)
+	oldToken := generateTokenWithID(secretKey, "old", issued)
+	freshToken := generateTokenWithID(secretKey, "fresh", time.Now().Add(time.Second))
+
+	if err := store.Revoke(ctx, "revoked", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.RevokeUser(ctx, 12345, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		wantStatus     int
+		wantOptionalID bool
+	}{
+		{name: "Revoked Token", token: revokedToken, wantStatus: http.StatusUnauthorized},
+		{name: "Issued Before User Revocation", token: oldToken, wantStatus: http.StatusUnauthorized},
+		{name: "Token Without ID", token: generateValidToken(secretKey), wantStatus: http.StatusUnauthorized},
+		{name: "Issued After User Revocation", token: freshToken, wantStatus: http.StatusOK, wantOptionalID: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTokenID string
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTokenID, _ = r.Context().Value("tokenID").(string)
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			resp := httptest.NewRecorder()
+			AuthMiddleware(jwtkeys.HMAC(secretKey).Keyfunc, store)(handler).ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.Code)
+			}
+			if tt.wantStatus == http.StatusOK && gotTokenID != "fresh" {
+				t.Errorf("expected token id in context, got %q", gotTokenID)
+			}
+
+			optional := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, ok := r.Context().Value("userID").(int64)
+				if ok != tt.wantOptionalID {
+					t.Errorf("expected userID presence: %v, got: %v", tt.wantOptionalID, ok)
+				}
+			})
+			OptionalAuthMiddleware(jwtkeys.HMAC(secretKey).Keyfunc, store)(optional).ServeHTTP(httptest.NewRecorder(), req)
+		})
+	}
+}
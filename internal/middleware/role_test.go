@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func generateTokenWithRole(secretKey, role string) string {
+	claims := jwt.MapClaims{
+		"sub": 12345,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	if role != "" {
+		claims["role"] = role
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, _ := token.SignedString([]byte(secretKey))
+	return tokenString
+}
+
+func TestRequireRole(t *testing.T) {
+	secretKey := "mysecret"
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	protected := AuthMiddleware(jwtkeys.HMAC(secretKey).Keyfunc, nil)(RequireRole("moderator", "admin")(handler))
+
+	tests := []struct {
+		name       string
+		role       string
+		wantStatus int
+	}{
+		{name: "Admin", role: "admin", wantStatus: http.StatusOK},
+		{name: "Moderator", role: "moderator", wantStatus: http.StatusOK},
+		{name: "User", role: "user", wantStatus: http.StatusForbidden},
+		{name: "Token Without Role", role: "", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+generateTokenWithRole(secretKey, tt.role))
+			resp := httptest.NewRecorder()
+
+			protected.ServeHTTP(resp, req)
+
+			if resp.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.Code)
+			}
+		})
+	}
+
+	t.Run("Without Auth Middleware", func(t *testing.T) {
+		resp := httptest.NewRecorder()
+		RequireRole("admin")(handler).ServeHTTP(resp, httptest.NewRequest("GET", "/protected", nil))
+
+		if resp.Code != http.StatusUnauthorized {
+			t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.Code)
+		}
+	})
+}
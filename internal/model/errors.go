@@ -0,0 +1,71 @@
+package model
+
+import "errors"
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrInvalidRole  = errors.New("invalid role")
+	ErrRoleLocked   = errors.New("role of a configured admin cannot be changed")
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+
+	ErrWrongPassword         = errors.New("password is incorrect")
+	ErrInvalidResetToken     = errors.New("invalid or expired password reset token")
+	ErrPasswordResetDisabled = errors.New("password reset is not configured")
+
+	ErrEmailTaken                = errors.New("email is already in use")
+	ErrEmailAlreadyVerified      = errors.New("email is already verified")
+	ErrEmailNotVerified          = errors.New("verified email required")
+	ErrInvalidVerificationToken  = errors.New("invalid or expired email verification token")
+	ErrEmailVerificationDisabled = errors.New("email verification is not configured")
+
+	ErrAdNotFound  = errors.New("ad not found")
+	ErrNotAdAuthor = errors.New("only the author can modify this ad")
+	ErrEmptyUpdate = errors.New("nothing to update")
+
+	ErrNotModerator        = errors.New("moderator access required")
+	ErrInvalidAdTransition = errors.New("ad status transition is not allowed")
+	ErrAdStatusChanged     = errors.New("ad status was changed concurrently")
+
+	ErrCursorMismatch = errors.New("cursor does not match filters")
+
+	ErrDuplicateReport = errors.New("you already have an open report for this ad")
+	ErrReportOwnAd     = errors.New("you cannot report your own ad")
+
+	ErrThreadNotFound       = errors.New("thread not found")
+	ErrNotThreadParticipant = errors.New("only thread participants can access this thread")
+	ErrMessageOwnAd         = errors.New("you cannot start a thread about your own ad")
+
+	ErrDuplicateReview  = errors.New("you have already reviewed the seller for this ad")
+	ErrReviewOwnAd      = errors.New("you cannot review yourself")
+	ErrReviewNotAllowed = errors.New("only buyers who contacted the seller about this ad can leave a review")
+
+	ErrCategoryNotFound = errors.New("category not found")
+
+	ErrNoContentPolicy = errors.New("content policy is not configured")
+
+	ErrImageTooLarge        = errors.New("image is too large")
+	ErrUnsupportedImageType = errors.New("unsupported image format")
+	ErrImageDimensions      = errors.New("image dimensions exceed the allowed limit")
+	ErrImageNotUploaded     = errors.New("image_url must reference an uploaded image")
+
+	ErrTooManyImages   = errors.New("too many images")
+	ErrNoImages        = errors.New("ad must have at least one image")
+	ErrDuplicateImage  = errors.New("duplicate image in gallery")
+	ErrAdImageNotFound = errors.New("image not found")
+
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrBaseCurrencyRate    = errors.New("base currency rate is fixed at 1")
+	ErrNotAdmin            = errors.New("admin access required")
+)
+
+type FieldError struct {
+	Field  string
+	Reason string
+	Rule   string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
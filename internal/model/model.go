@@ -2,33 +2,248 @@ package model
 
 import (
 	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+const (
+	AdStatusPending   = "pending"
+	AdStatusPublished = "published"
+	AdStatusRejected  = "rejected"
+	AdStatusArchived  = "archived"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
 )
 
+var ReportReasons = []string{"scam", "prohibited", "spam", "offensive", "wrong_category", "other"}
+
 type User struct {
-	ID           int64     `db:"id"`
-	Login        string    `db:"login"`
-	PasswordHash string    `db:"password_hash"`
-	CreatedAt    time.Time `db:"created_at"`
+	ID              int64      `db:"id"`
+	Login           string     `db:"login"`
+	PasswordHash    string     `db:"password_hash"`
+	Role            string     `db:"role"`
+	Email           string     `db:"email"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+}
+
+type RefreshToken struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
+
+type PasswordReset struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+type EmailVerification struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	Email     string     `db:"email"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
 }
 
 type Ad struct {
+	ID              int64        `db:"id"`
+	Title           string       `db:"title"`
+	Description     string       `db:"description"`
+	ImageURL        string       `db:"image_url"`
+	Price           money.Amount `db:"price"`
+	Currency        string       `db:"currency"`
+	AuthorID        int64        `db:"author_id"`
+	CategoryID      int64        `db:"category_id"`
+	Status          string       `db:"status"`
+	RejectionReason string       `db:"rejection_reason"`
+	PolicyMatches   []string     `db:"policy_matches"`
+	CreatedAt       time.Time    `db:"created_at"`
+	UpdatedAt       time.Time    `db:"updated_at"`
+	ImageVariants   ImageVariants
+	Images          []AdImage
+}
+
+type AdWithAuthor struct {
+	ID              int64
+	Title           string
+	Description     string
+	ImageURL        string
+	ImageVariants   ImageVariants
+	Price           money.Amount
+	Currency        string
+	DisplayPrice    money.Amount
+	AuthorID        int64
+	CategoryID      int64
+	Status          string
+	RejectionReason string
+	PolicyMatches   []string
+	HiddenAt        *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	AuthorLogin     string
+	AuthorRating    *float64
+	Rank            float32
+	IsFavorite      bool
+	Images          []AdImage
+}
+
+type AdStatusChange struct {
+	AdID      int64
+	From      string
+	To        string
+	Reason    string
+	ChangedBy int64
+	ChangedAt time.Time
+}
+
+type Report struct {
+	ID         int64      `db:"id"`
+	AdID       int64      `db:"ad_id"`
+	ReporterID int64      `db:"reporter_id"`
+	Reason     string     `db:"reason"`
+	Comment    string     `db:"comment"`
+	Status     string     `db:"status"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ClosedBy   int64      `db:"closed_by"`
+	ClosedAt   *time.Time `db:"closed_at"`
+}
+
+type ReportedAd struct {
+	Ad             *AdWithAuthor
+	OpenReports    int64
+	Reasons        []string
+	LastReportedAt time.Time
+}
+
+type ReportedAdList struct {
+	Items []*ReportedAd
+	Total int64
+}
+
+type Thread struct {
+	ID            int64     `db:"id"`
+	AdID          int64     `db:"ad_id"`
+	AdTitle       string    `db:"ad_title"`
+	BuyerID       int64     `db:"buyer_id"`
+	BuyerLogin    string    `db:"buyer_login"`
+	SellerID      int64     `db:"seller_id"`
+	SellerLogin   string    `db:"seller_login"`
+	CreatedAt     time.Time `db:"created_at"`
+	LastMessageAt time.Time `db:"last_message_at"`
+	LastMessage   *Message
+	Unread        int64
+}
+
+type Message struct {
+	ID        int64     `db:"id"`
+	ThreadID  int64     `db:"thread_id"`
+	SenderID  int64     `db:"sender_id"`
+	Body      string    `db:"body"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type ThreadList struct {
+	Items  []*Thread
+	Total  int64
+	Unread int64
+}
+
+type Review struct {
+	ID            int64     `db:"id"`
+	AdID          int64     `db:"ad_id"`
+	AdTitle       string    `db:"ad_title"`
+	SellerID      int64     `db:"seller_id"`
+	ReviewerID    int64     `db:"reviewer_id"`
+	ReviewerLogin string    `db:"reviewer_login"`
+	Rating        int       `db:"rating"`
+	Text          string    `db:"text"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+type ReviewList struct {
+	Items []Review
+	Total int64
+}
+
+type Profile struct {
+	ID              int64      `db:"id"`
+	Login           string     `db:"login"`
+	Role            string     `db:"role"`
+	DisplayName     string     `db:"display_name"`
+	About           string     `db:"about"`
+	AvatarURL       string     `db:"avatar_url"`
+	Location        string     `db:"location"`
+	Email           string     `db:"email"`
+	EmailVerifiedAt *time.Time `db:"email_verified_at"`
+	Rating          *float64   `db:"rating"`
+	ReviewsCount    int64      `db:"reviews_count"`
+	CreatedAt       time.Time  `db:"created_at"`
+}
+
+type AdImage struct {
+	ID       int64  `db:"id"`
+	AdID     int64  `db:"ad_id"`
+	URL      string `db:"url"`
+	Position int    `db:"position"`
+	Variants ImageVariants
+}
+
+type AdList struct {
+	Ads        []*AdWithAuthor
+	Total      int64
+	NextCursor string
+}
+
+type Category struct {
+	ID       int64  `db:"id"`
+	Name     string `db:"name"`
+	Slug     string `db:"slug"`
+	ParentID int64  `db:"parent_id"`
+	Children []*Category
+}
+
+type Image struct {
 	ID          int64     `db:"id"`
-	Title       string    `db:"title"`
-	Description string    `db:"description"`
-	ImageURL    string    `db:"image_url"`
-	Price       float64   `db:"price"`
-	AuthorID    int64     `db:"author_id"`
+	OwnerID     int64     `db:"owner_id"`
+	StorageKey  string    `db:"storage_key"`
+	URL         string    `db:"url"`
+	ContentType string    `db:"content_type"`
+	SizeBytes   int64     `db:"size_bytes"`
+	Width       int       `db:"width"`
+	Height      int       `db:"height"`
 	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	Variants    ImageVariants
 }
 
-type AdWithAuthor struct {
-	ID          int64
-	Title       string
-	Description string
-	ImageURL    string
-	Price       float64
-	AuthorID    int64
-	CreatedAt   time.Time
-	AuthorLogin string
+type ImageVariants struct {
+	Thumbnail string `db:"thumbnail_url"`
+	Medium    string `db:"medium_url"`
+}
+
+type ExchangeRate struct {
+	Currency  string    `db:"currency"`
+	Rate      string    `db:"rate"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
@@ -0,0 +1,56 @@
+package money
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	BaseCurrency = "RUB"
+
+	RateScale = 8
+
+	maxRateDigits = 10
+)
+
+var (
+	ErrInvalidCurrency = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrInvalidRate     = errors.New("rate must be a positive decimal number with at most 8 decimal places")
+)
+
+func ParseCurrency(s string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(s))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
+
+func ParseRate(s string) (string, error) {
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || (hasFrac && (frac == "" || !isDigits(frac))) {
+		return "", ErrInvalidRate
+	}
+
+	whole = strings.TrimLeft(whole, "0")
+	frac = strings.TrimRight(frac, "0")
+	if len(whole) > maxRateDigits || len(frac) > RateScale {
+		return "", ErrInvalidRate
+	}
+	if whole == "" && frac == "" {
+		return "", ErrInvalidRate
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+	if frac == "" {
+		return whole, nil
+	}
+	return whole + "." + frac, nil
+}
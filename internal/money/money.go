@@ -0,0 +1,150 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	Scale = 2
+
+	MaxAmount Amount = 99_999_999_99
+
+	unitsPerMajor = 100
+)
+
+var (
+	ErrInvalid  = errors.New("amount must be a decimal number like 1234.56")
+	ErrNegative = errors.New("amount must not be negative")
+	ErrScale    = fmt.Errorf("amount must have at most %d decimal places", Scale)
+	ErrTooLarge = errors.New("amount must not exceed 99999999.99")
+)
+
+type Amount int64
+
+func Parse(s string) (Amount, error) {
+	amount, err := parseUnits(s)
+	if err != nil {
+		return 0, err
+	}
+	if amount > MaxAmount {
+		return 0, ErrTooLarge
+	}
+	return amount, nil
+}
+
+func (a Amount) Validate() error {
+	if a < 0 {
+		return ErrNegative
+	}
+	if a > MaxAmount {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+func (a Amount) String() string {
+	sign := ""
+	units := int64(a)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/unitsPerMajor, units%unitsPerMajor)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	case int64:
+		*a = Amount(v * unitsPerMajor)
+		return nil
+	case nil:
+		*a = 0
+		return nil
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) scanString(s string) error {
+	negative := strings.HasPrefix(s, "-")
+	parsed, err := parseUnits(strings.TrimPrefix(s, "-"))
+	if err != nil {
+		return fmt.Errorf("money: cannot scan %q: %w", s, err)
+	}
+	if negative {
+		parsed = -parsed
+	}
+	*a = parsed
+	return nil
+}
+
+func parseUnits(s string) (Amount, error) {
+	if s == "" {
+		return 0, ErrInvalid
+	}
+	if s[0] == '-' {
+		return 0, ErrNegative
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" || !isDigits(whole) || (hasFrac && (frac == "" || !isDigits(frac))) {
+		return 0, ErrInvalid
+	}
+
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > Scale {
+		return 0, ErrScale
+	}
+	frac += strings.Repeat("0", Scale-len(frac))
+
+	units, err := strconv.ParseInt(whole+frac, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, ErrTooLarge
+	}
+	if err != nil {
+		return 0, ErrInvalid
+	}
+	return Amount(units), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
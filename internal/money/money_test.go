@@ -0,0 +1,131 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr error
+	}{
+		{in: "0", want: 0},
+		{in: "100", want: 10000},
+		{in: "0.1", want: 10},
+		{in: "0.30", want: 30},
+		{in: "12.345", wantErr: ErrScale},
+		{in: "12.340", want: 1234},
+		{in: "99999999.99", want: MaxAmount},
+		{in: "100000000", wantErr: ErrTooLarge},
+		{in: "000000001.5", want: 150},
+		{in: "-5", wantErr: ErrNegative},
+		{in: "", wantErr: ErrInvalid},
+		{in: "abc", wantErr: ErrInvalid},
+		{in: "1.", wantErr: ErrInvalid},
+		{in: ".5", wantErr: ErrInvalid},
+		{in: "1e3", wantErr: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	var req struct {
+		Price Amount `json:"price"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": 0.1}`), &req))
+	assert.Equal(t, Amount(10), req.Price)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"price": "25.50"}`), &req))
+	assert.Equal(t, Amount(2550), req.Price)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"price": 1.005}`), &req), ErrScale)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"price": 123456789}`), &req), ErrTooLarge)
+
+	out, err := json.Marshal(req)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"price": 25.50}`, string(out))
+}
+
+func TestAmount_Scan(t *testing.T) {
+	var a Amount
+
+	assert.NoError(t, a.Scan([]byte("1234.50")))
+	assert.Equal(t, Amount(123450), a)
+
+	assert.NoError(t, a.Scan("0.30"))
+	assert.Equal(t, Amount(30), a)
+
+	assert.NoError(t, a.Scan(int64(7)))
+	assert.Equal(t, Amount(700), a)
+
+	v, err := Amount(30).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.30", v)
+}
+
+func TestAmount_ScanConverted(t *testing.T) {
+	var a Amount
+
+	assert.NoError(t, a.Scan("950000000.00"))
+	assert.Equal(t, Amount(95000000000), a)
+
+	assert.ErrorIs(t, a.Scan("1.005"), ErrScale)
+}
+
+func TestParseCurrency(t *testing.T) {
+	code, err := ParseCurrency(" usd ")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", code)
+
+	for _, in := range []string{"", "US", "USDT", "U$D", "рубль"} {
+		_, err := ParseCurrency(in)
+		assert.ErrorIs(t, err, ErrInvalidCurrency, in)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1", want: "1"},
+		{in: "92.50", want: "92.5"},
+		{in: "0.01085000", want: "0.01085"},
+		{in: "007.1", want: "7.1"},
+		{in: "0.123456789", wantErr: true},
+		{in: "0", wantErr: true},
+		{in: "0.000", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "1e2", wantErr: true},
+		{in: "12345678901", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidRate)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifier(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "outbox", "notifications.jsonl")
+
+	n, err := NewFileNotifier(path)
+	require.NoError(t, err)
+
+	sentAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, n.Notify(ctx, Message{UserID: 1, Login: "petr", Subject: "first", Body: "a", SentAt: sentAt}))
+	require.NoError(t, n.Notify(ctx, Message{UserID: 2, Login: "pavel", Subject: "second", Body: "b"}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var got []Message
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var msg Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &msg))
+		got = append(got, msg)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, got, 2)
+	assert.Equal(t, Message{UserID: 1, Login: "petr", Subject: "first", Body: "a", SentAt: sentAt}, got[0])
+	assert.Equal(t, "pavel", got[1].Login)
+	assert.False(t, got[1].SentAt.IsZero(), "missing send time is filled in")
+}
+
+func TestLogNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := NewLogNotifier(log.New(&buf, "", 0))
+
+	require.NoError(t, n.Notify(context.Background(), Message{UserID: 1, Login: "petr", Subject: "Password reset", Body: "code: 123"}))
+	assert.Equal(t, "notification for petr (user 1): Password reset\ncode: 123\n", buf.String())
+}
@@ -0,0 +1,93 @@
+package revocation
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	sweepInterval   = time.Minute
+	defaultTokenTTL = 15 * time.Minute
+)
+
+type userCutoff struct {
+	before    time.Time
+	expiresAt time.Time
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	tokenTTL  time.Duration
+	tokens    map[string]time.Time
+	users     map[int64]userCutoff
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore(tokenTTL time.Duration) *MemoryStore {
+	if tokenTTL <= 0 {
+		tokenTTL = defaultTokenTTL
+	}
+	return &MemoryStore{
+		tokenTTL: tokenTTL,
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int64]userCutoff),
+		now:      time.Now,
+	}
+}
+
+func (m *MemoryStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep()
+	if current, ok := m.tokens[jti]; !ok || expiresAt.After(current) {
+		m.tokens[jti] = expiresAt
+	}
+	return nil
+}
+
+func (m *MemoryStore) RevokeUser(ctx context.Context, userID int64, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep()
+	if current, ok := m.users[userID]; !ok || before.After(current.before) {
+		m.users[userID] = userCutoff{before: before, expiresAt: before.Add(m.tokenTTL)}
+	}
+	return nil
+}
+
+func (m *MemoryStore) IsRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if expiresAt, ok := m.tokens[jti]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+	if cutoff, ok := m.users[userID]; ok && now.Before(cutoff.expiresAt) && !issuedAt.After(cutoff.before) {
+		return true, nil
+	}
+	return false, nil
+}
+
+func (m *MemoryStore) sweep() {
+	now := m.now()
+	if now.Before(m.nextSweep) {
+		return
+	}
+	m.nextSweep = now.Add(sweepInterval)
+
+	for jti, expiresAt := range m.tokens {
+		if !now.Before(expiresAt) {
+			delete(m.tokens, jti)
+		}
+	}
+	for userID, cutoff := range m.users {
+		if !now.Before(cutoff.expiresAt) {
+			delete(m.users, userID)
+		}
+	}
+}
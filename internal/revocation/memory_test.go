@@ -0,0 +1,60 @@
+package revocation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore(15 * time.Minute)
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.Revoke(ctx, "a", now.Add(10*time.Minute)))
+
+	revoked, err := store.IsRevoked(ctx, "a", 1, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	revoked, _ = store.IsRevoked(ctx, "b", 1, now.Add(-time.Minute))
+	assert.False(t, revoked)
+
+	assert.NoError(t, store.RevokeUser(ctx, 1, now))
+
+	revoked, _ = store.IsRevoked(ctx, "b", 1, now.Add(-time.Minute))
+	assert.True(t, revoked, "tokens issued before the cutoff are revoked")
+
+	revoked, _ = store.IsRevoked(ctx, "c", 1, now.Add(time.Second))
+	assert.False(t, revoked, "tokens issued after the cutoff stay valid")
+
+	revoked, _ = store.IsRevoked(ctx, "b", 2, now.Add(-time.Minute))
+	assert.False(t, revoked, "other users are not affected")
+
+	now = now.Add(20 * time.Minute)
+	assert.NoError(t, store.Revoke(ctx, "d", now.Add(time.Minute)))
+
+	assert.NotContains(t, store.tokens, "a")
+	assert.NotContains(t, store.users, int64(1))
+}
+
+func TestMemoryStore_DefaultTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store := NewMemoryStore(0)
+	store.now = func() time.Time { return now }
+
+	assert.NoError(t, store.RevokeUser(ctx, 1, now))
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, store.Revoke(ctx, "a", now.Add(time.Minute)))
+
+	revoked, err := store.IsRevoked(ctx, "b", 1, now.Add(-5*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, revoked, "cutoff survives a sweep when access_ttl is unset")
+}
@@ -0,0 +1,12 @@
+package revocation
+
+import (
+	"context"
+	"time"
+)
+
+type Store interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	RevokeUser(ctx context.Context, userID int64, before time.Time) error
+	IsRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error)
+}
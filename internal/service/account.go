@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/notify"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLen = 6
+
+func WithNotifier(n notify.Notifier) Option {
+	return func(s *Service) {
+		s.notifier = n
+	}
+}
+
+func (s *Service) ChangePassword(ctx context.Context, currentPassword, newPassword string, userID int64) error {
+	if err := validatePassword("new_password", newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword {
+		return &model.FieldError{Field: "new_password", Reason: "must differ from the current password"}
+	}
+
+	if err := s.checkPassword(ctx, userID, currentPassword); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	if err := s.storage.UpdatePassword(ctx, userID, string(hash)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.revokeSessions(ctx, userID, time.Now())
+}
+
+func (s *Service) RequestPasswordReset(ctx context.Context, login string) error {
+	if s.notifier == nil {
+		return model.ErrPasswordResetDisabled
+	}
+
+	user, err := s.storage.GetUserByLogin(ctx, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	now := time.Now()
+	reset := &model.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.auth.PasswordResetTTL),
+		CreatedAt: now,
+	}
+	if err := s.storage.CreatePasswordReset(ctx, reset); err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, notify.Message{
+		UserID:  user.ID,
+		Login:   user.Login,
+		Subject: "Password reset",
+		Body: fmt.Sprintf(
+			"Use this token to reset your password: %s\nIt expires at %s. If you did not request a reset, ignore this message.",
+			token, reset.ExpiresAt.UTC().Format(time.RFC3339),
+		),
+		SentAt: now,
+	})
+}
+
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validatePassword("new_password", newPassword); err != nil {
+		return err
+	}
+
+	reset, err := s.storage.GetPasswordReset(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	if reset.UsedAt != nil || !now.Before(reset.ExpiresAt) {
+		return model.ErrInvalidResetToken
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	if err := s.storage.ResetPassword(ctx, reset, string(hash), now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrInvalidResetToken
+		}
+		return err
+	}
+
+	return s.revokeSessions(ctx, reset.UserID, now)
+}
+
+func (s *Service) DeleteAccount(ctx context.Context, password string, userID int64) error {
+	if err := s.checkPassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	suffix, err := randomToken(9)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	now := time.Now()
+	if err := s.storage.AnonymizeUser(ctx, userID, "deleted-"+suffix, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrUserNotFound
+		}
+		return err
+	}
+
+	return s.revokeSessions(ctx, userID, now)
+}
+
+func (s *Service) checkPassword(ctx context.Context, userID int64, password string) error {
+	user, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrUserNotFound
+		}
+		return err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return model.ErrWrongPassword
+	}
+	return nil
+}
+
+func (s *Service) revokeSessions(ctx context.Context, userID int64, now time.Time) error {
+	if s.revoked != nil {
+		if err := s.revoked.RevokeUser(ctx, userID, now); err != nil {
+			return err
+		}
+	}
+	return s.storage.RevokeUserRefreshTokens(ctx, userID, now)
+}
+
+func validatePassword(field, password string) error {
+	if len(password) < minPasswordLen {
+		return &model.FieldError{Field: field, Reason: fmt.Sprintf("must be at least %d characters", minPasswordLen)}
+	}
+	return nil
+}
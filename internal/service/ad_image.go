@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const maxAdImages = 10
+
+func (s *Service) ReplaceAdImages(ctx context.Context, adID int64, urls []string, userID int64) ([]model.AdImage, error) {
+	current, err := s.GetAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canModify(ctx, current.AuthorID, userID); err != nil {
+		return nil, err
+	}
+
+	gallery, err := s.buildGallery(ctx, urls, current.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var change *model.AdStatusChange
+	if current.AuthorID == userID {
+		status, err := s.statusAfterEdit(ctx, current.Status, current.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if change, err = editStatusChange(current, status, userID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.storage.ReplaceAdImages(ctx, adID, current.AuthorID, current.Status, gallery, change, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.editConflict(ctx, adID)
+		}
+		return nil, err
+	}
+
+	return gallery, nil
+}
+
+func (s *Service) DeleteAdImage(ctx context.Context, adID, imageID int64, userID int64) ([]model.AdImage, error) {
+	current, err := s.GetAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canModify(ctx, current.AuthorID, userID); err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	found := false
+	for _, img := range current.Images {
+		if img.ID == imageID {
+			found = true
+			continue
+		}
+		urls = append(urls, img.URL)
+	}
+	if !found {
+		return nil, model.ErrAdImageNotFound
+	}
+	if len(urls) == 0 {
+		return nil, model.ErrNoImages
+	}
+
+	return s.ReplaceAdImages(ctx, adID, urls, userID)
+}
+
+func (s *Service) buildGallery(ctx context.Context, urls []string, userID int64) ([]model.AdImage, error) {
+	if len(urls) == 0 {
+		return nil, model.ErrNoImages
+	}
+	if len(urls) > maxAdImages {
+		return nil, model.ErrTooManyImages
+	}
+
+	seen := make(map[string]struct{}, len(urls))
+	gallery := make([]model.AdImage, 0, len(urls))
+	for i, url := range urls {
+		if _, ok := seen[url]; ok {
+			return nil, model.ErrDuplicateImage
+		}
+		seen[url] = struct{}{}
+
+		variants, err := s.resolveImage(ctx, url, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		gallery = append(gallery, model.AdImage{
+			URL:      url,
+			Position: i,
+			Variants: variants,
+		})
+	}
+
+	return gallery, nil
+}
+
+func (s *Service) attachImages(ctx context.Context, ads []*model.AdWithAuthor) error {
+	if len(ads) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(ads))
+	for _, a := range ads {
+		ids = append(ids, a.ID)
+	}
+
+	images, err := s.storage.GetAdImages(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range ads {
+		a.Images = images[a.ID]
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Service) GetCategoryTree(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.storage.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*model.Category, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	roots := make([]*model.Category, 0)
+	for _, c := range categories {
+		parent, ok := byID[c.ParentID]
+		if c.ParentID == 0 || !ok {
+			roots = append(roots, c)
+			continue
+		}
+		parent.Children = append(parent.Children, c)
+	}
+
+	return roots, nil
+}
+
+func (s *Service) validateCategory(ctx context.Context, id int64) error {
+	if _, err := s.storage.GetCategoryByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrCategoryNotFound
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+
+	"github.com/AugustSerenity/marketplace/internal/contentpolicy"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func WithContentPolicy(p *contentpolicy.Policy) Option {
+	return func(s *Service) {
+		s.content = p
+	}
+}
+
+func (s *Service) ReloadContentPolicy(ctx context.Context, userID int64) (int, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return 0, err
+	}
+	if s.content == nil {
+		return 0, model.ErrNoContentPolicy
+	}
+	if err := s.content.Reload(); err != nil {
+		return 0, err
+	}
+	return s.content.Len(), nil
+}
+
+func (s *Service) checkContent(title, description string) (string, string, []string, error) {
+	if s.content == nil {
+		return title, description, nil, nil
+	}
+
+	res := s.content.Check(title, description)
+	if m, ok := res.First(contentpolicy.ActionReject); ok {
+		return "", "", nil, &model.FieldError{
+			Field:  m.Field,
+			Reason: "contains prohibited content",
+			Rule:   m.Rule,
+		}
+	}
+
+	return res.Title, res.Description, res.Rules(contentpolicy.ActionModerate), nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/model"
@@ -10,6 +11,68 @@ import (
 type Storage interface {
 	CreateUser(ctx context.Context, user *model.User) error
 	GetUserByLogin(ctx context.Context, login string) (*model.User, error)
+	GetUserByID(ctx context.Context, id int64) (*model.User, error)
+	UpdateUserRole(ctx context.Context, id int64, role string) error
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, usedID int64, next *model.RefreshToken, usedAt time.Time) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64, revokedAt time.Time) error
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+	CreatePasswordReset(ctx context.Context, reset *model.PasswordReset) error
+	GetPasswordReset(ctx context.Context, tokenHash string) (*model.PasswordReset, error)
+	ResetPassword(ctx context.Context, reset *model.PasswordReset, passwordHash string, usedAt time.Time) error
+	EmailInUse(ctx context.Context, email string, exceptUserID int64) (bool, error)
+	UpdateEmail(ctx context.Context, userID int64, email string) error
+	CreateEmailVerification(ctx context.Context, v *model.EmailVerification) error
+	GetEmailVerification(ctx context.Context, tokenHash string) (*model.EmailVerification, error)
+	VerifyEmail(ctx context.Context, v *model.EmailVerification, verifiedAt time.Time) error
+	AnonymizeUser(ctx context.Context, userID int64, login string, deletedAt time.Time) error
 	CreateAd(ctx context.Context, ad *model.Ad) error
+	GetAdByID(ctx context.Context, id int64) (*model.AdWithAuthor, error)
+	UpdateAd(ctx context.Context, ad *model.Ad, change *model.AdStatusChange) error
+	DeleteAd(ctx context.Context, id, authorID int64) error
+	UpdateAdStatus(ctx context.Context, change *model.AdStatusChange) error
+	GetAdsByStatus(ctx context.Context, status string, offset, limit int) ([]*model.AdWithAuthor, error)
+	CountAdsByStatus(ctx context.Context, status string) (int64, error)
+	CreateReport(ctx context.Context, report *model.Report) error
+	CountOpenReports(ctx context.Context, adID int64) (int64, error)
+	HideAd(ctx context.Context, adID int64, hiddenAt time.Time) error
+	CloseAdReports(ctx context.Context, adID int64, status string, closedBy int64, closedAt time.Time, unhide bool) error
+	GetAdReports(ctx context.Context, adID int64) ([]model.Report, error)
+	GetReportedAds(ctx context.Context, offset, limit int) ([]*model.ReportedAd, error)
+	CountReportedAds(ctx context.Context) (int64, error)
+	AddFavorite(ctx context.Context, userID, adID int64, createdAt time.Time) error
+	RemoveFavorite(ctx context.Context, userID, adID int64) error
+	GetFavorites(ctx context.Context, userID int64, currency string, offset, limit int) ([]*model.AdWithAuthor, error)
+	CountFavorites(ctx context.Context, userID int64) (int64, error)
+	GetFavoriteUserIDs(ctx context.Context, adID int64) ([]int64, error)
+	CreateThread(ctx context.Context, thread *model.Thread) error
+	GetThread(ctx context.Context, id int64) (*model.Thread, error)
+	FindThread(ctx context.Context, adID, buyerID int64) (*model.Thread, error)
+	CreateMessage(ctx context.Context, msg *model.Message) error
+	GetMessages(ctx context.Context, threadID, afterID int64, limit int) ([]model.Message, error)
+	MarkThreadRead(ctx context.Context, threadID, userID int64) error
+	GetUserThreads(ctx context.Context, userID int64, offset, limit int) ([]*model.Thread, error)
+	CountUserThreads(ctx context.Context, userID int64) (int64, int64, error)
+	CreateReview(ctx context.Context, review *model.Review) error
+	GetProfile(ctx context.Context, login string) (*model.Profile, error)
+	GetProfileByID(ctx context.Context, id int64) (*model.Profile, error)
+	UpdateProfile(ctx context.Context, profile *model.Profile) error
+	GetSellerReviews(ctx context.Context, sellerID int64, offset, limit int) ([]model.Review, error)
 	GetAds(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error)
+	CountAds(ctx context.Context, req *ad.ListRequest) (int64, error)
+	GetCategories(ctx context.Context) ([]*model.Category, error)
+	GetCategoryByID(ctx context.Context, id int64) (*model.Category, error)
+	CreateImage(ctx context.Context, img *model.Image) error
+	GetImageByURL(ctx context.Context, url string) (*model.Image, error)
+	GetAdImages(ctx context.Context, adIDs []int64) (map[int64][]model.AdImage, error)
+	ReplaceAdImages(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error
+	GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error)
+	GetExchangeRate(ctx context.Context, currency string) (*model.ExchangeRate, error)
+	UpsertExchangeRate(ctx context.Context, rate *model.ExchangeRate) error
+}
+
+type Publisher interface {
+	Publish(userID int64, eventType string, payload any)
 }
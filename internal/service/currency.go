@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
+func (s *Service) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	return s.storage.GetExchangeRates(ctx)
+}
+
+func (s *Service) UpdateExchangeRate(ctx context.Context, currency, rate string, userID int64) (*model.ExchangeRate, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	code, err := money.ParseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	if code == money.BaseCurrency {
+		return nil, model.ErrBaseCurrencyRate
+	}
+
+	parsed, err := money.ParseRate(rate)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &model.ExchangeRate{
+		Currency:  code,
+		Rate:      parsed,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.storage.UpsertExchangeRate(ctx, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (s *Service) validateCurrency(ctx context.Context, code string) error {
+	if code == money.BaseCurrency {
+		return nil
+	}
+	if _, err := s.storage.GetExchangeRate(ctx, code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s", model.ErrUnsupportedCurrency, code)
+		}
+		return err
+	}
+	return nil
+}
+
+func displayCurrency(code string) string {
+	if code == "" {
+		return money.BaseCurrency
+	}
+	return code
+}
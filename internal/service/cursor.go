@@ -0,0 +1,100 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	defaultSortBy    = "created_at"
+	defaultSortOrder = "desc"
+)
+
+func (s *Service) encodeCursor(c ad.Cursor) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	return body + "." + s.signCursor(body), nil
+}
+
+func (s *Service) decodeCursor(token string) (*ad.Cursor, error) {
+	body, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.signCursor(body))) {
+		return nil, errors.New("invalid cursor value")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, errors.New("invalid cursor value")
+	}
+
+	var c ad.Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, errors.New("invalid cursor value")
+	}
+
+	return &c, nil
+}
+
+func (s *Service) signCursor(body string) string {
+	mac := hmac.New(sha256.New, []byte(s.secret))
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func cursorFor(req *ad.ListRequest, last *model.AdWithAuthor) ad.Cursor {
+	c := ad.Cursor{
+		SortBy:    req.SortBy,
+		SortOrder: req.SortOrder,
+		ID:        last.ID,
+		Currency:  req.Currency,
+		Filters:   filtersHash(req),
+	}
+
+	switch req.SortBy {
+	case "price":
+		c.Price = last.DisplayPrice
+	case "relevance":
+		c.Rank = last.Rank
+	default:
+		c.CreatedAt = last.CreatedAt
+	}
+
+	return c
+}
+
+func filtersHash(req *ad.ListRequest) string {
+	h := sha256.New()
+	for _, part := range []string{
+		req.Query,
+		strconv.FormatInt(req.Category, 10),
+		req.MinPrice.String(),
+		req.MaxPrice.String(),
+		strconv.FormatFloat(req.MinAuthorRating, 'g', -1, 64),
+		strconv.FormatInt(req.AuthorID, 10),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:12])
+}
+
+func normalizeSort(req *ad.ListRequest) {
+	if req.SortBy == "" {
+		req.SortBy = defaultSortBy
+	}
+	if req.SortOrder == "" {
+		req.SortOrder = defaultSortOrder
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/mail"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const maxEmailLen = 254
+
+func WithMailer(m mail.Mailer) Option {
+	return func(s *Service) {
+		s.mailer = m
+	}
+}
+
+func (s *Service) SetEmail(ctx context.Context, email string, userID int64) error {
+	if s.mailer == nil {
+		return model.ErrEmailVerificationDisabled
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrUserNotFound
+		}
+		return err
+	}
+
+	if user.Email != email {
+		if err := s.checkEmailAvailable(ctx, email, userID); err != nil {
+			return err
+		}
+		if err := s.storage.UpdateEmail(ctx, userID, email); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return model.ErrUserNotFound
+			}
+			return err
+		}
+		user.Email = email
+	} else if user.EmailVerifiedAt != nil {
+		return model.ErrEmailAlreadyVerified
+	}
+
+	return s.sendVerification(ctx, user, time.Now())
+}
+
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	v, err := s.storage.GetEmailVerification(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrInvalidVerificationToken
+		}
+		return err
+	}
+
+	now := time.Now()
+	if v.UsedAt != nil || !now.Before(v.ExpiresAt) {
+		return model.ErrInvalidVerificationToken
+	}
+
+	if err := s.storage.VerifyEmail(ctx, v, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrInvalidVerificationToken
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) sendVerification(ctx context.Context, user *model.User, now time.Time) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	v := &model.EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hashToken(token),
+		ExpiresAt: now.Add(s.auth.EmailVerificationTTL),
+		CreatedAt: now,
+	}
+	if err := s.storage.CreateEmailVerification(ctx, v); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      v.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\nuse this token to confirm your email: %s\nIt expires at %s. If you did not register, ignore this message.",
+			user.Login, token, v.ExpiresAt.UTC().Format(time.RFC3339),
+		),
+		SentAt: now,
+	})
+}
+
+func (s *Service) checkEmailAvailable(ctx context.Context, email string, userID int64) error {
+	taken, err := s.storage.EmailInUse(ctx, email, userID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return model.ErrEmailTaken
+	}
+	return nil
+}
+
+func (s *Service) requireVerifiedEmail(ctx context.Context, userID int64) error {
+	if !s.auth.RequireVerifiedEmail {
+		return nil
+	}
+
+	user, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrUserNotFound
+		}
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return model.ErrEmailNotVerified
+	}
+	return nil
+}
+
+func normalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if len(email) > maxEmailLen {
+		return "", &model.FieldError{Field: "email", Reason: fmt.Sprintf("must be at most %d characters", maxEmailLen)}
+	}
+	addr, err := netmail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", &model.FieldError{Field: "email", Reason: "must be a valid email address"}
+	}
+	return email, nil
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/AugustSerenity/marketplace/internal/events"
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/money"
+)
+
+func WithEvents(p Publisher) Option {
+	return func(s *Service) {
+		s.events = p
+	}
+}
+
+func (s *Service) publish(userID int64, eventType string, payload any) {
+	if s.events != nil {
+		s.events.Publish(userID, eventType, payload)
+	}
+}
+
+func (s *Service) notifyMessage(thread *model.Thread, msg *model.Message) {
+	payload := events.MessagePayload{
+		ThreadID:  thread.ID,
+		AdID:      thread.AdID,
+		MessageID: msg.ID,
+		SenderID:  msg.SenderID,
+		Body:      msg.Body,
+		CreatedAt: msg.CreatedAt,
+	}
+	s.publish(thread.BuyerID, events.TypeMessage, payload)
+	s.publish(thread.SellerID, events.TypeMessage, payload)
+}
+
+func (s *Service) notifyAdStatus(ad *model.AdWithAuthor) {
+	s.publish(ad.AuthorID, events.TypeAdStatus, events.AdStatusPayload{
+		AdID:   ad.ID,
+		Title:  ad.Title,
+		Status: ad.Status,
+		Reason: ad.RejectionReason,
+	})
+}
+
+func (s *Service) notifyPriceDrop(ctx context.Context, ad *model.Ad, oldPrice money.Amount) {
+	if s.events == nil {
+		return
+	}
+
+	userIDs, err := s.storage.GetFavoriteUserIDs(ctx, ad.ID)
+	if err != nil {
+		log.Printf("price drop notification for ad %d: %v", ad.ID, err)
+		return
+	}
+
+	payload := events.PriceDropPayload{
+		AdID:     ad.ID,
+		Title:    ad.Title,
+		OldPrice: oldPrice,
+		NewPrice: ad.Price,
+		Currency: ad.Currency,
+	}
+	for _, userID := range userIDs {
+		s.publish(userID, events.TypePriceDrop, payload)
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Service) AddFavorite(ctx context.Context, adID, userID int64) error {
+	ad, err := s.GetAd(ctx, adID)
+	if err != nil {
+		return err
+	}
+	if ad.Status != model.AdStatusPublished || ad.HiddenAt != nil {
+		return model.ErrAdNotFound
+	}
+
+	return s.storage.AddFavorite(ctx, userID, adID, time.Now())
+}
+
+func (s *Service) RemoveFavorite(ctx context.Context, adID, userID int64) error {
+	return s.storage.RemoveFavorite(ctx, userID, adID)
+}
+
+func (s *Service) GetFavorites(ctx context.Context, page, pageSize int, currency string, userID int64) (*model.AdList, error) {
+	currency = displayCurrency(currency)
+	if err := s.validateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+
+	ads, err := s.storage.GetFavorites(ctx, userID, currency, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.storage.CountFavorites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachImages(ctx, ads); err != nil {
+		return nil, err
+	}
+
+	return &model.AdList{Ads: ads, Total: total}, nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/blob"
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/imaging"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	defaultImageMaxBytes  = 5 << 20
+	defaultImageMaxWidth  = 4096
+	defaultImageMaxHeight = 4096
+
+	thumbnailSize = 200
+	mediumSize    = 800
+)
+
+var imageFormats = map[string]struct {
+	format string
+	ext    string
+}{
+	"image/jpeg": {format: "jpeg", ext: ".jpg"},
+	"image/png":  {format: "png", ext: ".png"},
+	"image/gif":  {format: "gif", ext: ".gif"},
+}
+
+func WithImages(store blob.Store, cfg config.Images) Option {
+	return func(s *Service) {
+		if cfg.MaxBytes <= 0 {
+			cfg.MaxBytes = defaultImageMaxBytes
+		}
+		if cfg.MaxWidth <= 0 {
+			cfg.MaxWidth = defaultImageMaxWidth
+		}
+		if cfg.MaxHeight <= 0 {
+			cfg.MaxHeight = defaultImageMaxHeight
+		}
+		s.images = store
+		s.imageCfg = cfg
+	}
+}
+
+func (s *Service) UploadImage(ctx context.Context, r io.Reader, userID int64) (*model.Image, error) {
+	if s.images == nil {
+		return nil, errors.New("image uploads are not configured")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.imageCfg.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.imageCfg.MaxBytes {
+		return nil, model.ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	kind, ok := imageFormats[contentType]
+	if !ok {
+		return nil, model.ErrUnsupportedImageType
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != kind.format {
+		return nil, model.ErrUnsupportedImageType
+	}
+	if cfg.Width > s.imageCfg.MaxWidth || cfg.Height > s.imageCfg.MaxHeight {
+		return nil, model.ErrImageDimensions
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, model.ErrUnsupportedImageType
+	}
+
+	baseKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	key := baseKey + kind.ext
+
+	url, err := s.images.Put(ctx, key, contentType, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{key}
+
+	cleanup := func() {
+		for _, k := range keys {
+			s.images.Delete(context.WithoutCancel(ctx), k)
+		}
+	}
+
+	var variants model.ImageVariants
+	for _, v := range []struct {
+		suffix string
+		size   int
+		url    *string
+	}{
+		{suffix: "_thumb.jpg", size: thumbnailSize, url: &variants.Thumbnail},
+		{suffix: "_medium.jpg", size: mediumSize, url: &variants.Medium},
+	} {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, imaging.Fit(decoded, v.size, v.size)); err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		variantURL, err := s.images.Put(ctx, baseKey+v.suffix, "image/jpeg", &buf)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		keys = append(keys, baseKey+v.suffix)
+		*v.url = variantURL
+	}
+
+	img := &model.Image{
+		OwnerID:     userID,
+		StorageKey:  key,
+		URL:         url,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		CreatedAt:   time.Now(),
+		Variants:    variants,
+	}
+
+	if err := s.storage.CreateImage(ctx, img); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	return img, nil
+}
+
+func (s *Service) resolveImage(ctx context.Context, url string, userID int64) (model.ImageVariants, error) {
+	if s.images == nil {
+		return model.ImageVariants{}, nil
+	}
+
+	img, err := s.storage.GetImageByURL(ctx, url)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if s.imageCfg.AllowExternalURLs {
+				return model.ImageVariants{}, nil
+			}
+			return model.ImageVariants{}, model.ErrImageNotUploaded
+		}
+		return model.ImageVariants{}, err
+	}
+	if img.OwnerID != userID {
+		return model.ImageVariants{}, model.ErrImageNotUploaded
+	}
+
+	return img.Variants, nil
+}
+
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const maxRejectionReasonLen = 500
+
+var adTransitions = map[string][]string{
+	model.AdStatusPending:   {model.AdStatusPublished, model.AdStatusRejected, model.AdStatusArchived},
+	model.AdStatusPublished: {model.AdStatusPending, model.AdStatusRejected, model.AdStatusArchived},
+	model.AdStatusRejected:  {model.AdStatusPending, model.AdStatusArchived},
+}
+
+func WithModeration(cfg config.Moderation) Option {
+	return func(s *Service) {
+		s.premoderated = make(map[string]bool, len(cfg.Categories))
+		for _, slug := range cfg.Categories {
+			s.premoderated[strings.ToLower(strings.TrimSpace(slug))] = true
+		}
+	}
+}
+
+func (s *Service) GetModerationQueue(ctx context.Context, page, pageSize int, userID int64) (*model.AdList, error) {
+	if err := s.requireModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	ads, err := s.storage.GetAdsByStatus(ctx, model.AdStatusPending, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.storage.CountAdsByStatus(ctx, model.AdStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachImages(ctx, ads); err != nil {
+		return nil, err
+	}
+
+	return &model.AdList{Ads: ads, Total: total}, nil
+}
+
+func (s *Service) ApproveAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+	if err := s.requireModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.changeAdStatus(ctx, id, model.AdStatusPublished, "", userID)
+}
+
+func (s *Service) RejectAd(ctx context.Context, id int64, reason string, userID int64) (*model.AdWithAuthor, error) {
+	if err := s.requireModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, &model.FieldError{Field: "reason", Reason: "is required"}
+	}
+	if utf8.RuneCountInString(reason) > maxRejectionReasonLen {
+		return nil, &model.FieldError{Field: "reason", Reason: fmt.Sprintf("must be at most %d characters", maxRejectionReasonLen)}
+	}
+
+	rejected, err := s.changeAdStatus(ctx, id, model.AdStatusRejected, reason, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.CloseAdReports(ctx, id, model.ReportStatusResolved, userID, time.Now(), false); err != nil {
+		return nil, err
+	}
+	return rejected, nil
+}
+
+func (s *Service) ArchiveAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+	current, err := s.GetAd(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canModify(ctx, current.AuthorID, userID); err != nil {
+		return nil, err
+	}
+	return s.changeAdStatus(ctx, id, model.AdStatusArchived, "", userID)
+}
+
+func (s *Service) GetVisibleAd(ctx context.Context, id, userID int64) (*model.AdWithAuthor, error) {
+	ad, err := s.GetAd(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if (ad.Status == model.AdStatusPublished && ad.HiddenAt == nil) || ad.AuthorID == userID {
+		return ad, nil
+	}
+
+	ok, err := s.hasRole(ctx, userID, model.RoleModerator, model.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, model.ErrAdNotFound
+	}
+	return ad, nil
+}
+
+func (s *Service) changeAdStatus(ctx context.Context, id int64, to, reason string, userID int64) (*model.AdWithAuthor, error) {
+	current, err := s.GetAd(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTransition(current.Status, to) {
+		return nil, fmt.Errorf("%w: %s to %s", model.ErrInvalidAdTransition, current.Status, to)
+	}
+
+	change := &model.AdStatusChange{
+		AdID:      id,
+		From:      current.Status,
+		To:        to,
+		Reason:    reason,
+		ChangedBy: userID,
+		ChangedAt: time.Now(),
+	}
+	if err := s.storage.UpdateAdStatus(ctx, change); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrAdStatusChanged
+		}
+		return nil, err
+	}
+
+	current.Status = to
+	current.RejectionReason = reason
+	if userID != current.AuthorID {
+		s.notifyAdStatus(current)
+	}
+	return current, nil
+}
+
+func (s *Service) requireModerator(ctx context.Context, userID int64) error {
+	ok, err := s.hasRole(ctx, userID, model.RoleModerator, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.ErrNotModerator
+	}
+	return nil
+}
+
+func (s *Service) initialStatus(ctx context.Context, categoryID int64) (string, error) {
+	premoderated, err := s.requiresPremoderation(ctx, categoryID)
+	if err != nil {
+		return "", err
+	}
+	if premoderated {
+		return model.AdStatusPending, nil
+	}
+	return model.AdStatusPublished, nil
+}
+
+func (s *Service) requiresPremoderation(ctx context.Context, categoryID int64) (bool, error) {
+	if len(s.premoderated) == 0 {
+		return false, nil
+	}
+
+	seen := make(map[int64]bool)
+	for id := categoryID; id != 0 && !seen[id]; {
+		seen[id] = true
+		category, err := s.storage.GetCategoryByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		if s.premoderated[category.Slug] {
+			return true, nil
+		}
+		id = category.ParentID
+	}
+	return false, nil
+}
+
+func (s *Service) statusAfterEdit(ctx context.Context, status string, categoryID int64) (string, error) {
+	switch status {
+	case model.AdStatusRejected:
+		return model.AdStatusPending, nil
+	case model.AdStatusPublished:
+		premoderated, err := s.requiresPremoderation(ctx, categoryID)
+		if err != nil {
+			return "", err
+		}
+		if premoderated {
+			return model.AdStatusPending, nil
+		}
+	}
+	return status, nil
+}
+
+func editStatusChange(current *model.AdWithAuthor, status string, userID int64, changedAt time.Time) (*model.AdStatusChange, error) {
+	if status == current.Status {
+		return nil, nil
+	}
+	if !canTransition(current.Status, status) {
+		return nil, model.ErrInvalidAdTransition
+	}
+	return &model.AdStatusChange{
+		AdID:      current.ID,
+		From:      current.Status,
+		To:        status,
+		ChangedBy: userID,
+		ChangedAt: changedAt,
+	}, nil
+}
+
+func (s *Service) editConflict(ctx context.Context, id int64) error {
+	if _, err := s.storage.GetAdByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrAdNotFound
+		}
+		return err
+	}
+	return model.ErrAdStatusChanged
+}
+
+func canTransition(from, to string) bool {
+	for _, allowed := range adTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxDisplayNameLen = 50
+	maxAboutLen       = 1000
+	maxLocationLen    = 100
+)
+
+func (s *Service) GetProfile(ctx context.Context, login string) (*model.Profile, error) {
+	profile, err := s.storage.GetProfile(ctx, login)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *Service) GetMe(ctx context.Context, userID int64) (*model.Profile, error) {
+	profile, err := s.storage.GetProfileByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *Service) UpdateProfile(ctx context.Context, req user.UpdateProfileRequest, userID int64) (*model.Profile, error) {
+	if req.DisplayName == nil && req.About == nil && req.AvatarURL == nil && req.Location == nil {
+		return nil, model.ErrEmptyUpdate
+	}
+
+	profile, err := s.GetMe(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		name, err := normalizeProfileLine("display_name", *req.DisplayName, maxDisplayNameLen)
+		if err != nil {
+			return nil, err
+		}
+		profile.DisplayName = name
+	}
+	if req.About != nil {
+		about := normalizeDescription(*req.About)
+		if n := utf8.RuneCountInString(about); n > maxAboutLen {
+			return nil, &model.FieldError{Field: "about", Reason: fmt.Sprintf("must be at most %d characters, got %d", maxAboutLen, n)}
+		}
+		profile.About = about
+	}
+	if req.Location != nil {
+		location, err := normalizeProfileLine("location", *req.Location, maxLocationLen)
+		if err != nil {
+			return nil, err
+		}
+		profile.Location = location
+	}
+	if req.AvatarURL != nil {
+		avatar := strings.TrimSpace(*req.AvatarURL)
+		if avatar != "" {
+			if u, err := url.Parse(avatar); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, &model.FieldError{Field: "avatar_url", Reason: "must be a valid http(s) URL"}
+			}
+			if _, err := s.resolveImage(ctx, avatar, userID); err != nil {
+				return nil, err
+			}
+		}
+		profile.AvatarURL = avatar
+	}
+
+	if err := s.storage.UpdateProfile(ctx, profile); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func (s *Service) GetUserAds(ctx context.Context, login string, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+	profile, err := s.GetProfile(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AuthorID = profile.ID
+	return s.GetAds(ctx, req, userID)
+}
+
+func normalizeProfileLine(field, value string, maxLen int) (string, error) {
+	value = norm.NFC.String(strings.Join(strings.Fields(stripInvisible(value, false)), " "))
+	if n := utf8.RuneCountInString(value); n > maxLen {
+		return "", &model.FieldError{Field: field, Reason: fmt.Sprintf("must be at most %d characters, got %d", maxLen, n)}
+	}
+	return value, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const (
+	defaultReportHideThreshold = 3
+	maxReportCommentLen        = 1000
+)
+
+func WithReports(cfg config.Reports) Option {
+	return func(s *Service) {
+		s.reports = cfg
+	}
+}
+
+func (s *Service) ReportAd(ctx context.Context, adID int64, reason, comment string, userID int64) (*model.Report, error) {
+	if !validReportReason(reason) {
+		return nil, &model.FieldError{Field: "reason", Reason: "must be one of " + strings.Join(model.ReportReasons, ", ")}
+	}
+	comment = strings.TrimSpace(normalizeDescription(comment))
+	if reason == "other" && comment == "" {
+		return nil, &model.FieldError{Field: "comment", Reason: "is required when reason is other"}
+	}
+	if utf8.RuneCountInString(comment) > maxReportCommentLen {
+		return nil, &model.FieldError{Field: "comment", Reason: fmt.Sprintf("must be at most %d characters", maxReportCommentLen)}
+	}
+
+	current, err := s.GetVisibleAd(ctx, adID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current.AuthorID == userID {
+		return nil, model.ErrReportOwnAd
+	}
+
+	now := time.Now()
+	report := &model.Report{
+		AdID:       adID,
+		ReporterID: userID,
+		Reason:     reason,
+		Comment:    comment,
+		Status:     model.ReportStatusOpen,
+		CreatedAt:  now,
+	}
+	if err := s.storage.CreateReport(ctx, report); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrDuplicateReport
+		}
+		return nil, err
+	}
+
+	open, err := s.storage.CountOpenReports(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if open >= int64(s.reportHideThreshold()) && current.HiddenAt == nil {
+		if err := s.storage.HideAd(ctx, adID, now); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+func (s *Service) GetReportedAds(ctx context.Context, page, pageSize int, userID int64) (*model.ReportedAdList, error) {
+	if err := s.requireModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	items, err := s.storage.GetReportedAds(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.storage.CountReportedAds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ads := make([]*model.AdWithAuthor, 0, len(items))
+	for _, item := range items {
+		ads = append(ads, item.Ad)
+	}
+	if err := s.attachImages(ctx, ads); err != nil {
+		return nil, err
+	}
+
+	return &model.ReportedAdList{Items: items, Total: total}, nil
+}
+
+func (s *Service) GetAdReports(ctx context.Context, adID, userID int64) ([]model.Report, error) {
+	if err := s.requireModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetAd(ctx, adID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetAdReports(ctx, adID)
+}
+
+func (s *Service) DismissReports(ctx context.Context, adID, userID int64) error {
+	if err := s.requireModerator(ctx, userID); err != nil {
+		return err
+	}
+	if _, err := s.GetAd(ctx, adID); err != nil {
+		return err
+	}
+	return s.storage.CloseAdReports(ctx, adID, model.ReportStatusDismissed, userID, time.Now(), true)
+}
+
+func (s *Service) reportHideThreshold() int {
+	if s.reports.HideThreshold <= 0 {
+		return defaultReportHideThreshold
+	}
+	return s.reports.HideThreshold
+}
+
+func validReportReason(reason string) bool {
+	for _, allowed := range model.ReportReasons {
+		if reason == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const maxReviewTextLen = 1000
+
+func (s *Service) CreateReview(ctx context.Context, adID int64, rating int, text string, userID int64) (*model.Review, error) {
+	if rating < 1 || rating > 5 {
+		return nil, &model.FieldError{Field: "rating", Reason: "must be between 1 and 5"}
+	}
+	text = strings.TrimSpace(normalizeDescription(text))
+	if n := utf8.RuneCountInString(text); n > maxReviewTextLen {
+		return nil, &model.FieldError{Field: "text", Reason: fmt.Sprintf("must be at most %d characters, got %d", maxReviewTextLen, n)}
+	}
+
+	ad, err := s.storage.GetAdByID(ctx, adID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrAdNotFound
+		}
+		return nil, err
+	}
+	if ad.AuthorID == userID {
+		return nil, model.ErrReviewOwnAd
+	}
+
+	if _, err := s.storage.FindThread(ctx, adID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrReviewNotAllowed
+		}
+		return nil, err
+	}
+
+	reviewer, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	review := &model.Review{
+		AdID:          ad.ID,
+		AdTitle:       ad.Title,
+		SellerID:      ad.AuthorID,
+		ReviewerID:    userID,
+		ReviewerLogin: reviewer.Login,
+		Rating:        rating,
+		Text:          text,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.storage.CreateReview(ctx, review); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrDuplicateReview
+		}
+		return nil, err
+	}
+
+	return review, nil
+}
+
+func (s *Service) GetSellerReviews(ctx context.Context, login string, page, pageSize int) (*model.ReviewList, error) {
+	profile, err := s.GetProfile(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.storage.GetSellerReviews(ctx, profile.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ReviewList{Items: reviews, Total: profile.ReviewsCount}, nil
+}
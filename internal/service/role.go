@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func WithAdmins(ids ...int64) Option {
+	return func(s *Service) {
+		if s.admins == nil {
+			s.admins = make(map[int64]bool, len(ids))
+		}
+		for _, id := range ids {
+			s.admins[id] = true
+		}
+	}
+}
+
+func (s *Service) SetUserRole(ctx context.Context, targetID int64, role string, userID int64) (*model.User, error) {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+	if !validRole(role) {
+		return nil, model.ErrInvalidRole
+	}
+	if s.admins[targetID] && role != model.RoleAdmin {
+		return nil, model.ErrRoleLocked
+	}
+
+	if err := s.storage.UpdateUserRole(ctx, targetID, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	user, err := s.storage.GetUserByID(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = s.roleOf(user)
+	return user, nil
+}
+
+func (s *Service) roleOf(user *model.User) string {
+	if s.admins[user.ID] {
+		return model.RoleAdmin
+	}
+	if user.Role == "" {
+		return model.RoleUser
+	}
+	return user.Role
+}
+
+func (s *Service) userRole(ctx context.Context, userID int64) (string, error) {
+	if s.admins[userID] {
+		return model.RoleAdmin, nil
+	}
+
+	user, err := s.storage.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.RoleUser, nil
+		}
+		return "", err
+	}
+	return s.roleOf(user), nil
+}
+
+func (s *Service) hasRole(ctx context.Context, userID int64, roles ...string) (bool, error) {
+	role, err := s.userRole(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, allowed := range roles {
+		if role == allowed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) requireAdmin(ctx context.Context, userID int64) error {
+	ok, err := s.hasRole(ctx, userID, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.ErrNotAdmin
+	}
+	return nil
+}
+
+func (s *Service) canModify(ctx context.Context, authorID, userID int64) error {
+	if authorID == userID {
+		return nil
+	}
+	ok, err := s.hasRole(ctx, userID, model.RoleModerator, model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.ErrNotAdAuthor
+	}
+	return nil
+}
+
+func validRole(role string) bool {
+	switch role {
+	case model.RoleUser, model.RoleModerator, model.RoleAdmin:
+		return true
+	}
+	return false
+}
@@ -2,29 +2,74 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"math"
 	"net/url"
-	"regexp"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/AugustSerenity/marketplace/internal/blob"
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/contentpolicy"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
+	"github.com/AugustSerenity/marketplace/internal/mail"
 	"github.com/AugustSerenity/marketplace/internal/model"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/AugustSerenity/marketplace/internal/money"
+	"github.com/AugustSerenity/marketplace/internal/notify"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const maxSearchQueryLen = 200
+
 type Service struct {
-	storage Storage
-	secret  string
+	storage  Storage
+	secret   string
+	images   blob.Store
+	imageCfg config.Images
+	admins   map[int64]bool
+	titles   config.Titles
+	auth     config.Auth
+	revoked  revocation.Store
+	signer   jwtkeys.Signer
+
+	premoderated map[string]bool
+	reports      config.Reports
+	content      *contentpolicy.Policy
+	events       Publisher
+	notifier     notify.Notifier
+	mailer       mail.Mailer
 }
 
-func New(st Storage, secret string) *Service {
-	return &Service{
+type Option func(*Service)
+
+func New(st Storage, secret string, opts ...Option) *Service {
+	s := &Service{
 		storage: st,
 		secret:  secret,
+		signer:  jwtkeys.HMAC(secret),
+		titles: config.Titles{
+			MaxLength:   maxTitleLength,
+			Punctuation: defaultTitlePunctuation,
+		},
+		auth: config.Auth{
+			AccessTTL:            defaultAccessTTL,
+			RefreshTTL:           defaultRefreshTTL,
+			PasswordResetTTL:     defaultPasswordResetTTL,
+			EmailVerificationTTL: defaultEmailVerificationTTL,
+		},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 func (s *Service) RegisterUser(ctx context.Context, req *auth.RegistrationRequest) (*auth.RegistrationResponse, error) {
@@ -46,60 +91,128 @@ func (s *Service) RegisterUser(ctx context.Context, req *auth.RegistrationReques
 		CreatedAt:    time.Now(),
 	}
 
+	if req.Email != "" {
+		if s.mailer == nil {
+			return nil, model.ErrEmailVerificationDisabled
+		}
+		email, err := normalizeEmail(req.Email)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkEmailAvailable(ctx, email, 0); err != nil {
+			return nil, err
+		}
+		user.Email = email
+	}
+
 	if err := s.storage.CreateUser(ctx, &user); err != nil {
+		if strings.Contains(err.Error(), "idx_users_email") {
+			return nil, model.ErrEmailTaken
+		}
 		return nil, err
 	}
 
-	return &auth.RegistrationResponse{
+	resp := &auth.RegistrationResponse{
 		ID:    user.ID,
 		Login: user.Login,
-	}, nil
+		Email: user.Email,
+	}
+	if user.Email != "" {
+		err := s.sendVerification(ctx, &user, user.CreatedAt)
+		if err != nil {
+			log.Printf("send verification email: %v", err)
+		}
+		sent := err == nil
+		resp.EmailVerificationSent = &sent
+	}
+
+	return resp, nil
 }
 
-func (s *Service) LoginUser(ctx context.Context, login, password string) (string, error) {
+func (s *Service) LoginUser(ctx context.Context, login, password string) (*auth.LoginResponse, error) {
 
 	user, err := s.storage.GetUserByLogin(ctx, login)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
-			return "", err
+			return nil, err
 		}
-		return "", errors.New("user not found")
+		return nil, errors.New("user not found")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid password")
+		return nil, errors.New("invalid password")
+	}
+
+	return s.issueTokens(ctx, user)
+}
+
+func (s *Service) CreateAd(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error) {
+	if err := s.requireVerifiedEmail(ctx, userID); err != nil {
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24).Unix(),
-	})
+	title, err := s.normalizeTitle(req.Title)
+	if err != nil {
+		return nil, err
+	}
 
-	tokenString, err := token.SignedString([]byte(s.secret))
+	description, err := normalizeAdDescription(req.Description)
 	if err != nil {
-		return "", errors.New("failed to generate token")
+		return nil, err
 	}
 
-	return tokenString, nil
-}
+	title, description, flagged, err := s.checkContent(title, description)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *Service) CreateAd(ctx context.Context, req ad.CreateRequest, userID int64) (*model.Ad, error) {
-	invalidTitleRegex := `[^a-zA-Z0-9\s]`
-	if matched, _ := regexp.MatchString(invalidTitleRegex, req.Title); matched {
-		return nil, errors.New("invalid title characters")
+	if err := validatePrice(req.Price); err != nil {
+		return nil, err
+	}
+
+	currency := displayCurrency(req.Currency)
+	if err := s.validateCurrency(ctx, currency); err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != 0 {
+		if err := s.validateCategory(ctx, req.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
+	urls := req.Images
+	if len(urls) == 0 {
+		urls = []string{req.ImageURL}
+	}
+	gallery, err := s.buildGallery(ctx, urls, userID)
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Price <= 0 {
-		return nil, errors.New("price must be positive")
+	status, err := s.initialStatus(ctx, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(flagged) > 0 {
+		status = model.AdStatusPending
 	}
 
+	now := time.Now()
 	ad := &model.Ad{
-		Title:       req.Title,
-		Description: req.Description,
-		ImageURL:    req.ImageURL,
-		Price:       req.Price,
-		AuthorID:    userID,
-		CreatedAt:   time.Now(),
+		Title:         title,
+		Description:   description,
+		ImageURL:      gallery[0].URL,
+		Price:         req.Price,
+		Currency:      currency,
+		AuthorID:      userID,
+		CategoryID:    req.CategoryID,
+		Status:        status,
+		PolicyMatches: flagged,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		ImageVariants: gallery[0].Variants,
+		Images:        gallery,
 	}
 
 	if err := s.storage.CreateAd(ctx, ad); err != nil {
@@ -109,20 +222,228 @@ func (s *Service) CreateAd(ctx context.Context, req ad.CreateRequest, userID int
 	return ad, nil
 }
 
-func (s *Service) GetAds(ctx context.Context, req *ad.ListRequest, userID int64) ([]*model.AdWithAuthor, error) {
+func (s *Service) GetAd(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+	ad, err := s.storage.GetAdByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrAdNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.attachImages(ctx, []*model.AdWithAuthor{ad}); err != nil {
+		return nil, err
+	}
+
+	return ad, nil
+}
+
+func (s *Service) UpdateAd(ctx context.Context, id int64, req ad.UpdateRequest, userID int64) (*model.Ad, error) {
+	if req.Title == nil && req.Description == nil && req.ImageURL == nil && req.Price == nil && req.Currency == nil && req.CategoryID == nil {
+		return nil, model.ErrEmptyUpdate
+	}
+
+	current, err := s.GetAd(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canModify(ctx, current.AuthorID, userID); err != nil {
+		return nil, err
+	}
+
+	updated := &model.Ad{
+		ID:              current.ID,
+		Title:           current.Title,
+		Description:     current.Description,
+		ImageURL:        current.ImageURL,
+		Price:           current.Price,
+		Currency:        current.Currency,
+		AuthorID:        current.AuthorID,
+		CategoryID:      current.CategoryID,
+		Status:          current.Status,
+		RejectionReason: current.RejectionReason,
+		PolicyMatches:   current.PolicyMatches,
+		CreatedAt:       current.CreatedAt,
+		UpdatedAt:       time.Now(),
+		ImageVariants:   current.ImageVariants,
+	}
+
+	if req.Title != nil {
+		title, err := s.normalizeTitle(*req.Title)
+		if err != nil {
+			return nil, err
+		}
+		updated.Title = title
+	}
+	if req.Description != nil {
+		description, err := normalizeAdDescription(*req.Description)
+		if err != nil {
+			return nil, err
+		}
+		updated.Description = description
+	}
+	if req.Title != nil || req.Description != nil {
+		title, description, flagged, err := s.checkContent(updated.Title, updated.Description)
+		if err != nil {
+			return nil, err
+		}
+		updated.Title = title
+		updated.Description = description
+		updated.PolicyMatches = flagged
+	}
+	if req.ImageURL != nil {
+		urls := []string{*req.ImageURL}
+		for _, img := range current.Images {
+			if img.Position != 0 && img.URL != *req.ImageURL {
+				urls = append(urls, img.URL)
+			}
+		}
+		gallery, err := s.buildGallery(ctx, urls, current.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		updated.ImageURL = gallery[0].URL
+		updated.ImageVariants = gallery[0].Variants
+		updated.Images = gallery
+	}
+	if req.Price != nil {
+		if err := validatePrice(*req.Price); err != nil {
+			return nil, err
+		}
+		updated.Price = *req.Price
+	}
+	if req.Currency != nil {
+		if err := s.validateCurrency(ctx, *req.Currency); err != nil {
+			return nil, err
+		}
+		updated.Currency = *req.Currency
+	}
+	if req.CategoryID != nil {
+		if err := s.validateCategory(ctx, *req.CategoryID); err != nil {
+			return nil, err
+		}
+		updated.CategoryID = *req.CategoryID
+	}
+
+	var change *model.AdStatusChange
+	if current.AuthorID == userID {
+		status, err := s.statusAfterEdit(ctx, current.Status, updated.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if status == model.AdStatusPublished && len(updated.PolicyMatches) > 0 {
+			status = model.AdStatusPending
+		}
+		if change, err = editStatusChange(current, status, userID, updated.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.storage.UpdateAd(ctx, updated, change); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.editConflict(ctx, id)
+		}
+		return nil, err
+	}
+
+	if change != nil {
+		updated.Status = change.To
+		updated.RejectionReason = ""
+	}
+
+	if updated.Images == nil {
+		updated.Images = current.Images
+	}
+
+	if updated.Status == model.AdStatusPublished && current.HiddenAt == nil &&
+		updated.Currency == current.Currency && updated.Price < current.Price {
+		s.notifyPriceDrop(ctx, updated, current.Price)
+	}
+
+	return updated, nil
+}
+
+func (s *Service) DeleteAd(ctx context.Context, id int64, userID int64) error {
+	current, err := s.GetAd(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.canModify(ctx, current.AuthorID, userID); err != nil {
+		return err
+	}
+
+	if err := s.storage.DeleteAd(ctx, id, current.AuthorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrAdNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func validatePrice(price money.Amount) error {
+	if price <= 0 {
+		return errors.New("price must be positive")
+	}
+	if err := price.Validate(); err != nil {
+		return fmt.Errorf("price: %w", err)
+	}
+	return nil
+}
+
+func priceParamError(name string, err error) error {
+	if errors.Is(err, money.ErrInvalid) {
+		return fmt.Errorf("invalid %s value", name)
+	}
+	return fmt.Errorf("invalid %s value: %w", name, err)
+}
+
+func (s *Service) GetAds(ctx context.Context, req *ad.ListRequest, userID int64) (*model.AdList, error) {
+	if req.After != nil && req.After.Filters != filtersHash(req) {
+		return nil, model.ErrCursorMismatch
+	}
+	normalizeSort(req)
+	req.Currency = displayCurrency(req.Currency)
+	if err := s.validateCurrency(ctx, req.Currency); err != nil {
+		return nil, err
+	}
 
 	offset := (req.Page - 1) * req.PageSize
+	if req.After != nil {
+		offset = 0
+	}
 	limit := req.PageSize
 
 	ads, err := s.storage.GetAds(
 		ctx,
-		req, userID, offset, limit,
+		req, userID, offset, limit+1,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return ads, nil
+	total, err := s.storage.CountAds(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &model.AdList{Ads: ads, Total: total}
+	if len(ads) > limit {
+		list.Ads = ads[:limit]
+	}
+	if err := s.attachImages(ctx, list.Ads); err != nil {
+		return nil, err
+	}
+	if len(ads) > limit {
+		next, err := s.encodeCursor(cursorFor(req, list.Ads[limit-1]))
+		if err != nil {
+			return nil, err
+		}
+		list.NextCursor = next
+	}
+
+	return list, nil
 }
 
 func (s *Service) ParseListRequest(q url.Values) (ad.ListRequest, error) {
@@ -148,27 +469,88 @@ func (s *Service) ParseListRequest(q url.Values) (ad.ListRequest, error) {
 	}
 
 	req.SortBy = q.Get("sort_by")
+	switch req.SortBy {
+	case "", "created_at", "price", "relevance":
+	default:
+		return req, errors.New("sort_by must be one of created_at, price, relevance")
+	}
+
 	req.SortOrder = q.Get("sort_order")
+	switch req.SortOrder {
+	case "", "asc", "desc":
+	default:
+		return req, errors.New("sort_order must be asc or desc")
+	}
 
-	if val := q.Get("min_price"); val != "" {
-		if parsed, err := strconv.ParseFloat(val, 64); err == nil && parsed >= 0 {
-			req.MinPrice = parsed
+	req.Query = strings.TrimSpace(q.Get("q"))
+	if utf8.RuneCountInString(req.Query) > maxSearchQueryLen {
+		return req, errors.New("q is too long")
+	}
+
+	if req.SortBy == "relevance" && req.Query == "" {
+		return req, errors.New("sort_by=relevance requires q")
+	}
+
+	if val := q.Get("category"); val != "" {
+		if parsed, err := strconv.ParseInt(val, 10, 64); err == nil && parsed > 0 {
+			req.Category = parsed
 		} else {
-			return req, errors.New("invalid min_price value")
+			return req, errors.New("invalid category value")
+		}
+	}
+
+	if val := q.Get("currency"); val != "" {
+		code, err := money.ParseCurrency(val)
+		if err != nil {
+			return req, errors.New("invalid currency value")
 		}
+		req.Currency = code
+	}
+
+	if val := q.Get("min_price"); val != "" {
+		parsed, err := money.Parse(val)
+		if err != nil {
+			return req, priceParamError("min_price", err)
+		}
+		req.MinPrice = parsed
 	}
 
 	if val := q.Get("max_price"); val != "" {
-		if parsed, err := strconv.ParseFloat(val, 64); err == nil && parsed >= 0 {
-			req.MaxPrice = parsed
-		} else {
-			return req, errors.New("invalid max_price value")
+		parsed, err := money.Parse(val)
+		if err != nil {
+			return req, priceParamError("max_price", err)
 		}
+		req.MaxPrice = parsed
+	}
+
+	if val := q.Get("min_author_rating"); val != "" {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(parsed) || parsed < 1 || parsed > 5 {
+			return req, errors.New("min_author_rating must be a number between 1 and 5")
+		}
+		req.MinAuthorRating = parsed
 	}
 
 	if req.MinPrice > req.MaxPrice && req.MaxPrice != 0 {
 		return req, errors.New("min_price cannot be greater than max_price")
 	}
 
+	if val := q.Get("cursor"); val != "" {
+		cursor, err := s.decodeCursor(val)
+		if err != nil {
+			return req, err
+		}
+
+		sorted := req
+		normalizeSort(&sorted)
+		if cursor.SortBy != sorted.SortBy || cursor.SortOrder != sorted.SortOrder ||
+			cursor.Currency != displayCurrency(req.Currency) {
+			return req, errors.New("cursor does not match sort parameters")
+		}
+
+		req.Cursor = val
+		req.After = cursor
+	}
+
 	return req, nil
 }
@@ -1,25 +1,110 @@
 package service_test
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
 	"net/url"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/contentpolicy"
+	"github.com/AugustSerenity/marketplace/internal/events"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/user"
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
+	"github.com/AugustSerenity/marketplace/internal/mail"
 	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/money"
+	"github.com/AugustSerenity/marketplace/internal/notify"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
 	"github.com/AugustSerenity/marketplace/internal/service"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
+func mustAmount(s string) money.Amount {
+	a, err := money.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
 type mockStorage struct {
 	CreateUserFunc     func(ctx context.Context, user *model.User) error
 	GetUserByLoginFunc func(ctx context.Context, login string) (*model.User, error)
+	GetUserByIDFunc    func(ctx context.Context, id int64) (*model.User, error)
+	UpdateRoleFunc     func(ctx context.Context, id int64, role string) error
+	CreateRefreshFunc  func(ctx context.Context, token *model.RefreshToken) error
+	GetRefreshFunc     func(ctx context.Context, tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshFunc  func(ctx context.Context, usedID int64, next *model.RefreshToken, usedAt time.Time) error
+	RevokeFamilyFunc   func(ctx context.Context, familyID string, revokedAt time.Time) error
+	RevokeUserFunc     func(ctx context.Context, userID int64, revokedAt time.Time) error
+	UpdatePasswordFunc func(ctx context.Context, userID int64, passwordHash string) error
+	CreateResetFunc    func(ctx context.Context, reset *model.PasswordReset) error
+	GetResetFunc       func(ctx context.Context, tokenHash string) (*model.PasswordReset, error)
+	ResetPasswordFunc  func(ctx context.Context, reset *model.PasswordReset, passwordHash string, usedAt time.Time) error
+	AnonymizeFunc      func(ctx context.Context, userID int64, login string, deletedAt time.Time) error
+	EmailInUseFunc     func(ctx context.Context, email string, exceptUserID int64) (bool, error)
+	UpdateEmailFunc    func(ctx context.Context, userID int64, email string) error
+	CreateVerifyFunc   func(ctx context.Context, v *model.EmailVerification) error
+	GetVerifyFunc      func(ctx context.Context, tokenHash string) (*model.EmailVerification, error)
+	VerifyEmailFunc    func(ctx context.Context, v *model.EmailVerification, verifiedAt time.Time) error
 	CreateAdFunc       func(ctx context.Context, ad *model.Ad) error
+	GetAdByIDFunc      func(ctx context.Context, id int64) (*model.AdWithAuthor, error)
+	UpdateAdFunc       func(ctx context.Context, ad *model.Ad, change *model.AdStatusChange) error
+	DeleteAdFunc       func(ctx context.Context, id, authorID int64) error
 	GetAdsFunc         func(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error)
+	CountAdsFunc       func(ctx context.Context, req *ad.ListRequest) (int64, error)
+	GetCategoriesFunc  func(ctx context.Context) ([]*model.Category, error)
+	GetCategoryFunc    func(ctx context.Context, id int64) (*model.Category, error)
+	CreateImageFunc    func(ctx context.Context, img *model.Image) error
+	GetImageByURLFunc  func(ctx context.Context, url string) (*model.Image, error)
+	GetAdImagesFunc    func(ctx context.Context, adIDs []int64) (map[int64][]model.AdImage, error)
+	ReplaceImagesFunc  func(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error
+	GetRatesFunc       func(ctx context.Context) ([]model.ExchangeRate, error)
+	GetRateFunc        func(ctx context.Context, currency string) (*model.ExchangeRate, error)
+	UpsertRateFunc     func(ctx context.Context, rate *model.ExchangeRate) error
+	UpdateStatusFunc   func(ctx context.Context, change *model.AdStatusChange) error
+	GetByStatusFunc    func(ctx context.Context, status string, offset, limit int) ([]*model.AdWithAuthor, error)
+	CountByStatusFunc  func(ctx context.Context, status string) (int64, error)
+	CreateReportFunc   func(ctx context.Context, report *model.Report) error
+	CountOpenFunc      func(ctx context.Context, adID int64) (int64, error)
+	HideAdFunc         func(ctx context.Context, adID int64, hiddenAt time.Time) error
+	CloseReportsFunc   func(ctx context.Context, adID int64, status string, closedBy int64, closedAt time.Time, unhide bool) error
+	GetAdReportsFunc   func(ctx context.Context, adID int64) ([]model.Report, error)
+	GetReportedFunc    func(ctx context.Context, offset, limit int) ([]*model.ReportedAd, error)
+	CountReportedFunc  func(ctx context.Context) (int64, error)
+	AddFavoriteFunc    func(ctx context.Context, userID, adID int64, createdAt time.Time) error
+	RemoveFavoriteFunc func(ctx context.Context, userID, adID int64) error
+	GetFavoritesFunc   func(ctx context.Context, userID int64, currency string, offset, limit int) ([]*model.AdWithAuthor, error)
+	CountFavoritesFunc func(ctx context.Context, userID int64) (int64, error)
+	FavoriteUsersFunc  func(ctx context.Context, adID int64) ([]int64, error)
+	CreateThreadFunc   func(ctx context.Context, thread *model.Thread) error
+	GetThreadFunc      func(ctx context.Context, id int64) (*model.Thread, error)
+	FindThreadFunc     func(ctx context.Context, adID, buyerID int64) (*model.Thread, error)
+	CreateMessageFunc  func(ctx context.Context, msg *model.Message) error
+	GetMessagesFunc    func(ctx context.Context, threadID, afterID int64, limit int) ([]model.Message, error)
+	MarkReadFunc       func(ctx context.Context, threadID, userID int64) error
+	GetThreadsFunc     func(ctx context.Context, userID int64, offset, limit int) ([]*model.Thread, error)
+	CountThreadsFunc   func(ctx context.Context, userID int64) (int64, int64, error)
+	CreateReviewFunc   func(ctx context.Context, review *model.Review) error
+	GetProfileFunc     func(ctx context.Context, login string) (*model.Profile, error)
+	GetProfileByIDFunc func(ctx context.Context, id int64) (*model.Profile, error)
+	UpdateProfileFunc  func(ctx context.Context, profile *model.Profile) error
+	GetReviewsFunc     func(ctx context.Context, sellerID int64, offset, limit int) ([]model.Review, error)
 }
 
 func (m *mockStorage) CreateUser(ctx context.Context, user *model.User) error {
@@ -30,14 +115,282 @@ func (m *mockStorage) GetUserByLogin(ctx context.Context, login string) (*model.
 	return m.GetUserByLoginFunc(ctx, login)
 }
 
+func (m *mockStorage) GetUserByID(ctx context.Context, id int64) (*model.User, error) {
+	if m.GetUserByIDFunc == nil {
+		return nil, sql.ErrNoRows
+	}
+	return m.GetUserByIDFunc(ctx, id)
+}
+
+func (m *mockStorage) UpdateUserRole(ctx context.Context, id int64, role string) error {
+	return m.UpdateRoleFunc(ctx, id, role)
+}
+
+func (m *mockStorage) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	return m.CreateRefreshFunc(ctx, token)
+}
+
+func (m *mockStorage) GetRefreshToken(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+	return m.GetRefreshFunc(ctx, tokenHash)
+}
+
+func (m *mockStorage) RotateRefreshToken(ctx context.Context, usedID int64, next *model.RefreshToken, usedAt time.Time) error {
+	return m.RotateRefreshFunc(ctx, usedID, next, usedAt)
+}
+
+func (m *mockStorage) RevokeRefreshTokenFamily(ctx context.Context, familyID string, revokedAt time.Time) error {
+	return m.RevokeFamilyFunc(ctx, familyID, revokedAt)
+}
+
+func (m *mockStorage) RevokeUserRefreshTokens(ctx context.Context, userID int64, revokedAt time.Time) error {
+	return m.RevokeUserFunc(ctx, userID, revokedAt)
+}
+
+func (m *mockStorage) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	return m.UpdatePasswordFunc(ctx, userID, passwordHash)
+}
+
+func (m *mockStorage) CreatePasswordReset(ctx context.Context, reset *model.PasswordReset) error {
+	return m.CreateResetFunc(ctx, reset)
+}
+
+func (m *mockStorage) GetPasswordReset(ctx context.Context, tokenHash string) (*model.PasswordReset, error) {
+	return m.GetResetFunc(ctx, tokenHash)
+}
+
+func (m *mockStorage) ResetPassword(ctx context.Context, reset *model.PasswordReset, passwordHash string, usedAt time.Time) error {
+	return m.ResetPasswordFunc(ctx, reset, passwordHash, usedAt)
+}
+
+func (m *mockStorage) AnonymizeUser(ctx context.Context, userID int64, login string, deletedAt time.Time) error {
+	return m.AnonymizeFunc(ctx, userID, login, deletedAt)
+}
+
+func (m *mockStorage) EmailInUse(ctx context.Context, email string, exceptUserID int64) (bool, error) {
+	return m.EmailInUseFunc(ctx, email, exceptUserID)
+}
+
+func (m *mockStorage) UpdateEmail(ctx context.Context, userID int64, email string) error {
+	return m.UpdateEmailFunc(ctx, userID, email)
+}
+
+func (m *mockStorage) CreateEmailVerification(ctx context.Context, v *model.EmailVerification) error {
+	return m.CreateVerifyFunc(ctx, v)
+}
+
+func (m *mockStorage) GetEmailVerification(ctx context.Context, tokenHash string) (*model.EmailVerification, error) {
+	return m.GetVerifyFunc(ctx, tokenHash)
+}
+
+func (m *mockStorage) VerifyEmail(ctx context.Context, v *model.EmailVerification, verifiedAt time.Time) error {
+	return m.VerifyEmailFunc(ctx, v, verifiedAt)
+}
+
 func (m *mockStorage) CreateAd(ctx context.Context, ad *model.Ad) error {
 	return m.CreateAdFunc(ctx, ad)
 }
 
+func (m *mockStorage) GetAdByID(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+	return m.GetAdByIDFunc(ctx, id)
+}
+
+func (m *mockStorage) UpdateAd(ctx context.Context, ad *model.Ad, change *model.AdStatusChange) error {
+	return m.UpdateAdFunc(ctx, ad, change)
+}
+
+func (m *mockStorage) DeleteAd(ctx context.Context, id, authorID int64) error {
+	return m.DeleteAdFunc(ctx, id, authorID)
+}
+
 func (m *mockStorage) GetAds(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
 	return m.GetAdsFunc(ctx, req, userID, offset, limit)
 }
 
+func (m *mockStorage) CountAds(ctx context.Context, req *ad.ListRequest) (int64, error) {
+	return m.CountAdsFunc(ctx, req)
+}
+
+func (m *mockStorage) GetCategories(ctx context.Context) ([]*model.Category, error) {
+	return m.GetCategoriesFunc(ctx)
+}
+
+func (m *mockStorage) GetCategoryByID(ctx context.Context, id int64) (*model.Category, error) {
+	return m.GetCategoryFunc(ctx, id)
+}
+
+func (m *mockStorage) CreateImage(ctx context.Context, img *model.Image) error {
+	return m.CreateImageFunc(ctx, img)
+}
+
+func (m *mockStorage) GetImageByURL(ctx context.Context, url string) (*model.Image, error) {
+	return m.GetImageByURLFunc(ctx, url)
+}
+
+func (m *mockStorage) GetAdImages(ctx context.Context, adIDs []int64) (map[int64][]model.AdImage, error) {
+	if m.GetAdImagesFunc == nil {
+		return nil, nil
+	}
+	return m.GetAdImagesFunc(ctx, adIDs)
+}
+
+func (m *mockStorage) ReplaceAdImages(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error {
+	return m.ReplaceImagesFunc(ctx, adID, authorID, status, images, change, updatedAt)
+}
+
+func (m *mockStorage) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	return m.GetRatesFunc(ctx)
+}
+
+func (m *mockStorage) GetExchangeRate(ctx context.Context, currency string) (*model.ExchangeRate, error) {
+	return m.GetRateFunc(ctx, currency)
+}
+
+func (m *mockStorage) UpsertExchangeRate(ctx context.Context, rate *model.ExchangeRate) error {
+	return m.UpsertRateFunc(ctx, rate)
+}
+
+func (m *mockStorage) UpdateAdStatus(ctx context.Context, change *model.AdStatusChange) error {
+	return m.UpdateStatusFunc(ctx, change)
+}
+
+func (m *mockStorage) GetAdsByStatus(ctx context.Context, status string, offset, limit int) ([]*model.AdWithAuthor, error) {
+	return m.GetByStatusFunc(ctx, status, offset, limit)
+}
+
+func (m *mockStorage) CountAdsByStatus(ctx context.Context, status string) (int64, error) {
+	return m.CountByStatusFunc(ctx, status)
+}
+
+func (m *mockStorage) CreateReport(ctx context.Context, report *model.Report) error {
+	return m.CreateReportFunc(ctx, report)
+}
+
+func (m *mockStorage) CountOpenReports(ctx context.Context, adID int64) (int64, error) {
+	return m.CountOpenFunc(ctx, adID)
+}
+
+func (m *mockStorage) HideAd(ctx context.Context, adID int64, hiddenAt time.Time) error {
+	return m.HideAdFunc(ctx, adID, hiddenAt)
+}
+
+func (m *mockStorage) CloseAdReports(ctx context.Context, adID int64, status string, closedBy int64, closedAt time.Time, unhide bool) error {
+	return m.CloseReportsFunc(ctx, adID, status, closedBy, closedAt, unhide)
+}
+
+func (m *mockStorage) GetAdReports(ctx context.Context, adID int64) ([]model.Report, error) {
+	return m.GetAdReportsFunc(ctx, adID)
+}
+
+func (m *mockStorage) GetReportedAds(ctx context.Context, offset, limit int) ([]*model.ReportedAd, error) {
+	return m.GetReportedFunc(ctx, offset, limit)
+}
+
+func (m *mockStorage) CountReportedAds(ctx context.Context) (int64, error) {
+	return m.CountReportedFunc(ctx)
+}
+
+func (m *mockStorage) AddFavorite(ctx context.Context, userID, adID int64, createdAt time.Time) error {
+	return m.AddFavoriteFunc(ctx, userID, adID, createdAt)
+}
+
+func (m *mockStorage) RemoveFavorite(ctx context.Context, userID, adID int64) error {
+	return m.RemoveFavoriteFunc(ctx, userID, adID)
+}
+
+func (m *mockStorage) GetFavorites(ctx context.Context, userID int64, currency string, offset, limit int) ([]*model.AdWithAuthor, error) {
+	return m.GetFavoritesFunc(ctx, userID, currency, offset, limit)
+}
+
+func (m *mockStorage) CountFavorites(ctx context.Context, userID int64) (int64, error) {
+	return m.CountFavoritesFunc(ctx, userID)
+}
+
+func (m *mockStorage) GetFavoriteUserIDs(ctx context.Context, adID int64) ([]int64, error) {
+	return m.FavoriteUsersFunc(ctx, adID)
+}
+
+func (m *mockStorage) CreateReview(ctx context.Context, review *model.Review) error {
+	return m.CreateReviewFunc(ctx, review)
+}
+
+func (m *mockStorage) GetProfile(ctx context.Context, login string) (*model.Profile, error) {
+	return m.GetProfileFunc(ctx, login)
+}
+
+func (m *mockStorage) GetProfileByID(ctx context.Context, id int64) (*model.Profile, error) {
+	return m.GetProfileByIDFunc(ctx, id)
+}
+
+func (m *mockStorage) UpdateProfile(ctx context.Context, profile *model.Profile) error {
+	return m.UpdateProfileFunc(ctx, profile)
+}
+
+func (m *mockStorage) GetSellerReviews(ctx context.Context, sellerID int64, offset, limit int) ([]model.Review, error) {
+	return m.GetReviewsFunc(ctx, sellerID, offset, limit)
+}
+
+func (m *mockStorage) CreateThread(ctx context.Context, thread *model.Thread) error {
+	return m.CreateThreadFunc(ctx, thread)
+}
+
+func (m *mockStorage) GetThread(ctx context.Context, id int64) (*model.Thread, error) {
+	return m.GetThreadFunc(ctx, id)
+}
+
+func (m *mockStorage) FindThread(ctx context.Context, adID, buyerID int64) (*model.Thread, error) {
+	return m.FindThreadFunc(ctx, adID, buyerID)
+}
+
+func (m *mockStorage) CreateMessage(ctx context.Context, msg *model.Message) error {
+	return m.CreateMessageFunc(ctx, msg)
+}
+
+func (m *mockStorage) GetMessages(ctx context.Context, threadID, afterID int64, limit int) ([]model.Message, error) {
+	return m.GetMessagesFunc(ctx, threadID, afterID, limit)
+}
+
+func (m *mockStorage) MarkThreadRead(ctx context.Context, threadID, userID int64) error {
+	return m.MarkReadFunc(ctx, threadID, userID)
+}
+
+func (m *mockStorage) GetUserThreads(ctx context.Context, userID int64, offset, limit int) ([]*model.Thread, error) {
+	return m.GetThreadsFunc(ctx, userID, offset, limit)
+}
+
+func (m *mockStorage) CountUserThreads(ctx context.Context, userID int64) (int64, int64, error) {
+	return m.CountThreadsFunc(ctx, userID)
+}
+
+type memoryBlobStore struct {
+	objects map[string][]byte
+}
+
+func (m *memoryBlobStore) Put(ctx context.Context, key, contentType string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	m.objects[key] = data
+	return "http://cdn.test/" + key, nil
+}
+
+func (m *memoryBlobStore) Delete(ctx context.Context, key string) error {
+	delete(m.objects, key)
+	return nil
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
 func TestService_RegisterUser(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -134,6 +487,9 @@ func TestService_LoginUser(t *testing.T) {
 						PasswordHash: string(hashedPassword),
 					}, nil
 				}
+				m.CreateRefreshFunc = func(ctx context.Context, token *model.RefreshToken) error {
+					return nil
+				}
 			},
 		},
 		{
@@ -183,77 +539,1885 @@ func TestService_LoginUser(t *testing.T) {
 			}
 
 			s := service.New(mock, "secret")
-			token, err := s.LoginUser(context.Background(), tt.login, tt.password)
+			resp, err := s.LoginUser(context.Background(), tt.login, tt.password)
 
 			if tt.expectedErr != "" {
 				assert.EqualError(t, err, tt.expectedErr)
-				assert.Empty(t, token)
+				assert.Nil(t, resp)
 			} else {
 				assert.NoError(t, err)
-				assert.NotEmpty(t, token)
+				assert.NotEmpty(t, resp.Token)
+				assert.NotEmpty(t, resp.RefreshToken)
+				assert.Equal(t, int64(15*60), resp.ExpiresIn)
 			}
 		})
 	}
 }
 
-func TestService_CreateAd(t *testing.T) {
-	validReq := ad.CreateRequest{
-		Title:       "Valid Title",
-		Description: "Valid description",
-		ImageURL:    "http://example.com/image.jpg",
-		Price:       100,
+func newRefreshTokenStorage(user *model.User) *mockStorage {
+	tokens := map[string]*model.RefreshToken{}
+	return &mockStorage{
+		GetUserByLoginFunc: func(ctx context.Context, login string) (*model.User, error) {
+			return user, nil
+		},
+		GetUserByIDFunc: func(ctx context.Context, id int64) (*model.User, error) {
+			return user, nil
+		},
+		CreateRefreshFunc: func(ctx context.Context, token *model.RefreshToken) error {
+			token.ID = int64(len(tokens) + 1)
+			tokens[token.TokenHash] = token
+			return nil
+		},
+		GetRefreshFunc: func(ctx context.Context, tokenHash string) (*model.RefreshToken, error) {
+			token, ok := tokens[tokenHash]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *token
+			return &copied, nil
+		},
+		RotateRefreshFunc: func(ctx context.Context, usedID int64, next *model.RefreshToken, usedAt time.Time) error {
+			for _, token := range tokens {
+				if token.ID == usedID {
+					if token.UsedAt != nil || token.RevokedAt != nil {
+						return sql.ErrNoRows
+					}
+					token.UsedAt = &usedAt
+				}
+			}
+			next.ID = int64(len(tokens) + 1)
+			tokens[next.TokenHash] = next
+			return nil
+		},
+		RevokeFamilyFunc: func(ctx context.Context, familyID string, revokedAt time.Time) error {
+			for _, token := range tokens {
+				if token.FamilyID == familyID && token.RevokedAt == nil {
+					token.RevokedAt = &revokedAt
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestService_RefreshTokens(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &model.User{ID: 7, Login: "petr", PasswordHash: string(hash)}
+	ctx := context.Background()
+
+	t.Run("rotation and reuse detection", func(t *testing.T) {
+		s := service.New(newRefreshTokenStorage(user), "secret")
+
+		login, err := s.LoginUser(ctx, "petr", "password")
+		require.NoError(t, err)
+
+		rotated, err := s.RefreshTokens(ctx, login.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, login.RefreshToken, rotated.RefreshToken)
+		assert.NotEmpty(t, rotated.Token)
+
+		_, err = s.RefreshTokens(ctx, login.RefreshToken)
+		assert.ErrorIs(t, err, model.ErrRefreshTokenReused)
+
+		_, err = s.RefreshTokens(ctx, rotated.RefreshToken)
+		assert.ErrorIs(t, err, model.ErrRefreshTokenReused)
+	})
+
+	t.Run("logout revokes the session", func(t *testing.T) {
+		s := service.New(newRefreshTokenStorage(user), "secret")
+
+		login, err := s.LoginUser(ctx, "petr", "password")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Logout(ctx, login.RefreshToken))
+		assert.NoError(t, s.Logout(ctx, "unknown"))
+
+		_, err = s.RefreshTokens(ctx, login.RefreshToken)
+		assert.ErrorIs(t, err, model.ErrRefreshTokenReused)
+	})
+
+	t.Run("separate logins are separate families", func(t *testing.T) {
+		s := service.New(newRefreshTokenStorage(user), "secret")
+
+		first, err := s.LoginUser(ctx, "petr", "password")
+		require.NoError(t, err)
+		second, err := s.LoginUser(ctx, "petr", "password")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Logout(ctx, first.RefreshToken))
+
+		_, err = s.RefreshTokens(ctx, second.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired and unknown tokens", func(t *testing.T) {
+		s := service.New(newRefreshTokenStorage(user), "secret", service.WithAuth(config.Auth{RefreshTTL: time.Nanosecond}))
+
+		login, err := s.LoginUser(ctx, "petr", "password")
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+
+		_, err = s.RefreshTokens(ctx, login.RefreshToken)
+		assert.ErrorIs(t, err, model.ErrInvalidRefreshToken)
+
+		_, err = s.RefreshTokens(ctx, "unknown")
+		assert.ErrorIs(t, err, model.ErrInvalidRefreshToken)
+	})
+}
+
+func TestService_LoginUser_Signer(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &model.User{ID: 7, Login: "petr", PasswordHash: string(hash)}
+
+	ring, err := jwtkeys.NewKeyring(t.TempDir(), jwtkeys.AlgEdDSA)
+	require.NoError(t, err)
+
+	s := service.New(newRefreshTokenStorage(user), "secret", service.WithSigner(ring))
+
+	login, err := s.LoginUser(context.Background(), "petr", "password")
+	require.NoError(t, err)
+
+	token, err := jwt.Parse(login.Token, ring.Keyfunc)
+	require.NoError(t, err)
+	assert.Equal(t, jwtkeys.AlgEdDSA, token.Method.Alg())
+	assert.Equal(t, ring.JWKS().Keys[0].Kid, token.Header["kid"])
+
+	_, err = jwt.Parse(login.Token, jwtkeys.HMAC("secret").Keyfunc)
+	assert.Error(t, err, "the shared secret no longer verifies access tokens")
+
+	assert.Len(t, s.PublicKeys().Keys, 1)
+	assert.Empty(t, service.New(nil, "secret").PublicKeys().Keys)
+}
+
+func TestService_RevokeAllTokens(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &model.User{ID: 7, Login: "petr", PasswordHash: string(hash)}
+	ctx := context.Background()
+
+	mock := newRefreshTokenStorage(user)
+	var revokedUser int64
+	mock.RevokeUserFunc = func(ctx context.Context, userID int64, revokedAt time.Time) error {
+		revokedUser = userID
+		return nil
+	}
+	store := revocation.NewMemoryStore(time.Hour)
+	s := service.New(mock, "secret", service.WithRevocation(store), service.WithAdmins(1))
+
+	login, err := s.LoginUser(ctx, "petr", "password")
+	require.NoError(t, err)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(login.Token, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	require.NoError(t, err)
+	jti, _ := claims["jti"].(string)
+	require.NotEmpty(t, jti)
+	iat, _ := claims["iat"].(float64)
+	issuedAt := time.UnixMilli(int64(iat * 1000))
+
+	assert.NoError(t, s.RevokeToken(ctx, jti, time.Now().Add(time.Minute)))
+	revoked, _ := store.IsRevoked(ctx, jti, 7, issuedAt)
+	assert.True(t, revoked)
+
+	assert.ErrorIs(t, s.RevokeUserTokens(ctx, 7, 2), model.ErrNotAdmin)
+	assert.NoError(t, s.RevokeUserTokens(ctx, 7, 1))
+	assert.Equal(t, int64(7), revokedUser)
+
+	revoked, _ = store.IsRevoked(ctx, "other", 7, issuedAt)
+	assert.True(t, revoked, "tokens issued before revoke-all are rejected")
+}
+
+func TestService_SetUserRole(t *testing.T) {
+	users := map[int64]*model.User{
+		1: {ID: 1, Login: "root", Role: model.RoleUser},
+		2: {ID: 2, Login: "anna", Role: model.RoleAdmin},
+		3: {ID: 3, Login: "petr", Role: model.RoleUser},
+		4: {ID: 4, Login: "ivan", Role: model.RoleModerator},
+	}
+
+	tests := []struct {
+		name        string
+		targetID    int64
+		role        string
+		userID      int64
+		expectedErr error
+	}{
+		{name: "configured admin promotes", targetID: 3, role: model.RoleModerator, userID: 1},
+		{name: "admin by role demotes", targetID: 4, role: model.RoleUser, userID: 2},
+		{name: "moderator is not an admin", targetID: 3, role: model.RoleModerator, userID: 4, expectedErr: model.ErrNotAdmin},
+		{name: "regular user", targetID: 3, role: model.RoleAdmin, userID: 3, expectedErr: model.ErrNotAdmin},
+		{name: "unknown role", targetID: 3, role: "owner", userID: 1, expectedErr: model.ErrInvalidRole},
+		{name: "configured admin cannot be demoted", targetID: 1, role: model.RoleUser, userID: 2, expectedErr: model.ErrRoleLocked},
+		{name: "user not found", targetID: 99, role: model.RoleUser, userID: 1, expectedErr: model.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored string
+			mock := &mockStorage{
+				GetUserByIDFunc: func(ctx context.Context, id int64) (*model.User, error) {
+					u, ok := users[id]
+					if !ok {
+						return nil, sql.ErrNoRows
+					}
+					copied := *u
+					if id == tt.targetID && stored != "" {
+						copied.Role = stored
+					}
+					return &copied, nil
+				},
+				UpdateRoleFunc: func(ctx context.Context, id int64, role string) error {
+					if _, ok := users[id]; !ok {
+						return sql.ErrNoRows
+					}
+					stored = role
+					return nil
+				},
+			}
+
+			s := service.New(mock, "secret", service.WithAdmins(1))
+			user, err := s.SetUserRole(context.Background(), tt.targetID, tt.role, tt.userID)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, stored)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.role, user.Role)
+			assert.Equal(t, tt.role, stored)
+		})
+	}
+}
+
+func TestService_LoginUser_RoleClaim(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		user     *model.User
+		wantRole string
+	}{
+		{name: "stored role", user: &model.User{ID: 7, Role: model.RoleModerator}, wantRole: model.RoleModerator},
+		{name: "missing role", user: &model.User{ID: 8}, wantRole: model.RoleUser},
+		{name: "configured admin", user: &model.User{ID: 1, Role: model.RoleUser}, wantRole: model.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.user.Login = "petr"
+			tt.user.PasswordHash = string(hash)
+			s := service.New(newRefreshTokenStorage(tt.user), "secret", service.WithAdmins(1))
+
+			login, err := s.LoginUser(ctx, "petr", "password")
+			require.NoError(t, err)
+			refreshed, err := s.RefreshTokens(ctx, login.RefreshToken)
+			require.NoError(t, err)
+
+			for _, token := range []string{login.Token, refreshed.Token} {
+				claims := jwt.MapClaims{}
+				_, err := jwt.ParseWithClaims(token, claims, jwtkeys.HMAC("secret").Keyfunc)
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantRole, claims["role"])
+			}
+		})
+	}
+}
+
+func newModerationStorage(ads map[int64]*model.AdWithAuthor, roles map[int64]string) *mockStorage {
+	categories := map[int64]*model.Category{
+		1: {ID: 1, Slug: "electronics"},
+		4: {ID: 4, Slug: "phones", ParentID: 1},
+		2: {ID: 2, Slug: "books"},
 	}
+	return &mockStorage{
+		GetCategoryFunc: func(ctx context.Context, id int64) (*model.Category, error) {
+			c, ok := categories[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			return c, nil
+		},
+		GetUserByIDFunc: func(ctx context.Context, id int64) (*model.User, error) {
+			return &model.User{ID: id, Role: roles[id]}, nil
+		},
+		CreateAdFunc: func(ctx context.Context, a *model.Ad) error {
+			a.ID = int64(len(ads) + 1)
+			ads[a.ID] = &model.AdWithAuthor{
+				ID:            a.ID,
+				Title:         a.Title,
+				Description:   a.Description,
+				AuthorID:      a.AuthorID,
+				CategoryID:    a.CategoryID,
+				Status:        a.Status,
+				PolicyMatches: a.PolicyMatches,
+			}
+			return nil
+		},
+		GetAdByIDFunc: func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+			a, ok := ads[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *a
+			return &copied, nil
+		},
+		UpdateAdFunc: func(ctx context.Context, a *model.Ad, change *model.AdStatusChange) error {
+			stored := ads[a.ID]
+			if stored == nil || stored.Status != a.Status {
+				return sql.ErrNoRows
+			}
+			if change != nil {
+				if change.From != stored.Status {
+					return sql.ErrNoRows
+				}
+				stored.Status = change.To
+				stored.RejectionReason = change.Reason
+			}
+			stored.Title = a.Title
+			stored.Description = a.Description
+			stored.PolicyMatches = a.PolicyMatches
+			return nil
+		},
+		UpdateStatusFunc: func(ctx context.Context, change *model.AdStatusChange) error {
+			a := ads[change.AdID]
+			if a == nil || a.Status != change.From {
+				return sql.ErrNoRows
+			}
+			a.Status = change.To
+			a.RejectionReason = change.Reason
+			return nil
+		},
+		CloseReportsFunc: func(ctx context.Context, adID int64, status string, closedBy int64, closedAt time.Time, unhide bool) error {
+			if unhide {
+				ads[adID].HiddenAt = nil
+			}
+			return nil
+		},
+	}
+}
+
+func TestService_Favorites(t *testing.T) {
+	ctx := context.Background()
+	hiddenAt := time.Now()
+	ads := map[int64]*model.AdWithAuthor{
+		1: {ID: 1, AuthorID: 42, Status: model.AdStatusPublished},
+		2: {ID: 2, AuthorID: 42, Status: model.AdStatusPending},
+		3: {ID: 3, AuthorID: 42, Status: model.AdStatusPublished, HiddenAt: &hiddenAt},
+	}
+	favorites := map[int64]bool{}
+
+	var gotOffset, gotLimit int
+	var gotCurrency string
+	st := &mockStorage{
+		GetAdByIDFunc: func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+			a, ok := ads[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			return a, nil
+		},
+		AddFavoriteFunc: func(ctx context.Context, userID, adID int64, createdAt time.Time) error {
+			favorites[adID] = true
+			return nil
+		},
+		RemoveFavoriteFunc: func(ctx context.Context, userID, adID int64) error {
+			delete(favorites, adID)
+			return nil
+		},
+		GetFavoritesFunc: func(ctx context.Context, userID int64, currency string, offset, limit int) ([]*model.AdWithAuthor, error) {
+			gotCurrency, gotOffset, gotLimit = currency, offset, limit
+			return []*model.AdWithAuthor{{ID: 1, IsFavorite: true}}, nil
+		},
+		CountFavoritesFunc: func(ctx context.Context, userID int64) (int64, error) {
+			return 1, nil
+		},
+		GetRateFunc: func(ctx context.Context, currency string) (*model.ExchangeRate, error) {
+			if currency != "USD" {
+				return nil, sql.ErrNoRows
+			}
+			return &model.ExchangeRate{Currency: currency}, nil
+		},
+	}
+	s := service.New(st, "secret")
+
+	tests := []struct {
+		name        string
+		adID        int64
+		expectedErr error
+	}{
+		{name: "published ad", adID: 1},
+		{name: "pending ad", adID: 2, expectedErr: model.ErrAdNotFound},
+		{name: "hidden ad", adID: 3, expectedErr: model.ErrAdNotFound},
+		{name: "missing ad", adID: 99, expectedErr: model.ErrAdNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.AddFavorite(ctx, tt.adID, 5)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.False(t, favorites[tt.adID])
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, favorites[tt.adID])
+		})
+	}
+
+	list, err := s.GetFavorites(ctx, 3, 10, "USD", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Total)
+	assert.True(t, list.Ads[0].IsFavorite)
+	assert.Equal(t, "USD", gotCurrency)
+	assert.Equal(t, 20, gotOffset)
+	assert.Equal(t, 10, gotLimit)
+
+	_, err = s.GetFavorites(ctx, 1, 10, "JPY", 5)
+	assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+
+	require.NoError(t, s.RemoveFavorite(ctx, 1, 5))
+	assert.Empty(t, favorites)
+}
+
+func newThreadStorage(ads map[int64]*model.AdWithAuthor) *mockStorage {
+	threads := map[int64]*model.Thread{}
+	var messages []model.Message
+	lastRead := map[[2]int64]int64{}
+
+	return &mockStorage{
+		GetAdByIDFunc: func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+			a, ok := ads[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			return a, nil
+		},
+		CreateThreadFunc: func(ctx context.Context, thread *model.Thread) error {
+			for _, t := range threads {
+				if t.AdID == thread.AdID && t.BuyerID == thread.BuyerID {
+					return sql.ErrNoRows
+				}
+			}
+			thread.ID = int64(len(threads) + 1)
+			copied := *thread
+			threads[thread.ID] = &copied
+			return nil
+		},
+		FindThreadFunc: func(ctx context.Context, adID, buyerID int64) (*model.Thread, error) {
+			for _, t := range threads {
+				if t.AdID == adID && t.BuyerID == buyerID {
+					copied := *t
+					return &copied, nil
+				}
+			}
+			return nil, sql.ErrNoRows
+		},
+		GetThreadFunc: func(ctx context.Context, id int64) (*model.Thread, error) {
+			t, ok := threads[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *t
+			return &copied, nil
+		},
+		CreateMessageFunc: func(ctx context.Context, msg *model.Message) error {
+			msg.ID = int64(len(messages) + 1)
+			messages = append(messages, *msg)
+			threads[msg.ThreadID].LastMessageAt = msg.CreatedAt
+			lastRead[[2]int64{msg.ThreadID, msg.SenderID}] = msg.ID
+			return nil
+		},
+		GetMessagesFunc: func(ctx context.Context, threadID, afterID int64, limit int) ([]model.Message, error) {
+			var result []model.Message
+			for _, m := range messages {
+				if m.ThreadID == threadID && m.ID > afterID && len(result) < limit {
+					result = append(result, m)
+				}
+			}
+			return result, nil
+		},
+		MarkReadFunc: func(ctx context.Context, threadID, userID int64) error {
+			for _, m := range messages {
+				if m.ThreadID == threadID {
+					lastRead[[2]int64{threadID, userID}] = m.ID
+				}
+			}
+			return nil
+		},
+		GetThreadsFunc: func(ctx context.Context, userID int64, offset, limit int) ([]*model.Thread, error) {
+			var result []*model.Thread
+			for _, t := range threads {
+				if t.BuyerID != userID && t.SellerID != userID {
+					continue
+				}
+				copied := *t
+				for _, m := range messages {
+					if m.ThreadID == t.ID && m.SenderID != userID && m.ID > lastRead[[2]int64{t.ID, userID}] {
+						copied.Unread++
+					}
+				}
+				result = append(result, &copied)
+			}
+			return result, nil
+		},
+		CountThreadsFunc: func(ctx context.Context, userID int64) (int64, int64, error) {
+			var total, unread int64
+			for _, t := range threads {
+				if t.BuyerID != userID && t.SellerID != userID {
+					continue
+				}
+				total++
+				for _, m := range messages {
+					if m.ThreadID == t.ID && m.SenderID != userID && m.ID > lastRead[[2]int64{t.ID, userID}] {
+						unread++
+					}
+				}
+			}
+			return total, unread, nil
+		},
+	}
+}
+
+func TestService_Threads(t *testing.T) {
+	ctx := context.Background()
+	ads := map[int64]*model.AdWithAuthor{
+		1: {ID: 1, Title: "Bike", AuthorID: 42, Status: model.AdStatusPublished},
+		2: {ID: 2, Title: "Sofa", AuthorID: 42, Status: model.AdStatusPending},
+	}
+	s := service.New(newThreadStorage(ads), "secret")
+
+	t.Run("start validation", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			adID        int64
+			message     string
+			userID      int64
+			expectedErr error
+		}{
+			{name: "own ad", adID: 1, message: "Hi", userID: 42, expectedErr: model.ErrMessageOwnAd},
+			{name: "unpublished ad", adID: 2, message: "Hi", userID: 5, expectedErr: model.ErrAdNotFound},
+			{name: "missing ad", adID: 99, message: "Hi", userID: 5, expectedErr: model.ErrAdNotFound},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, _, err := s.StartThread(ctx, tt.adID, tt.message, tt.userID)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			})
+		}
+
+		_, _, err := s.StartThread(ctx, 1, " \u200b ", 5)
+		var fieldErr *model.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "message", fieldErr.Field)
+	})
+
+	thread, created, err := s.StartThread(ctx, 1, "Is it still available?", 5)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(5), thread.BuyerID)
+	assert.Equal(t, int64(42), thread.SellerID)
+	require.NotNil(t, thread.LastMessage)
+
+	again, created, err := s.StartThread(ctx, 1, "Hello?", 5)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, thread.ID, again.ID)
+
+	list, err := s.GetThreads(ctx, 1, 20, 42)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Total)
+	assert.Equal(t, int64(2), list.Unread)
+	assert.Equal(t, int64(2), list.Items[0].Unread)
+
+	list, err = s.GetThreads(ctx, 1, 20, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), list.Unread, "own messages are never unread")
+
+	_, err = s.PostMessage(ctx, thread.ID, "Yes", 42)
+	require.NoError(t, err)
+	_, err = s.PostMessage(ctx, thread.ID, "Me too", 6)
+	assert.ErrorIs(t, err, model.ErrNotThreadParticipant)
+	_, err = s.PostMessage(ctx, 99, "Hi", 42)
+	assert.ErrorIs(t, err, model.ErrThreadNotFound)
+
+	_, err = s.GetThreadMessages(ctx, thread.ID, 0, 50, 6)
+	assert.ErrorIs(t, err, model.ErrNotThreadParticipant)
+
+	messages, err := s.GetThreadMessages(ctx, thread.ID, 1, 50, 5)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Hello?", messages[0].Body)
+	assert.Equal(t, int64(42), messages[1].SenderID)
+
+	list, err = s.GetThreads(ctx, 1, 20, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Unread)
+
+	require.NoError(t, s.MarkThreadRead(ctx, thread.ID, 5))
+	list, err = s.GetThreads(ctx, 1, 20, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), list.Unread)
+
+	assert.ErrorIs(t, s.MarkThreadRead(ctx, thread.ID, 6), model.ErrNotThreadParticipant)
+}
+
+type recordingNotifier struct {
+	messages []notify.Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+func newAccountStorage(password string) (*mockStorage, *model.User) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	u := &model.User{ID: 1, Login: "petr", PasswordHash: string(hash)}
+	resets := map[string]*model.PasswordReset{}
+
+	return &mockStorage{
+		GetUserByIDFunc: func(ctx context.Context, id int64) (*model.User, error) {
+			if id != u.ID {
+				return nil, sql.ErrNoRows
+			}
+			return u, nil
+		},
+		GetUserByLoginFunc: func(ctx context.Context, login string) (*model.User, error) {
+			if login != u.Login {
+				return nil, sql.ErrNoRows
+			}
+			return u, nil
+		},
+		UpdatePasswordFunc: func(ctx context.Context, userID int64, passwordHash string) error {
+			u.PasswordHash = passwordHash
+			return nil
+		},
+		CreateResetFunc: func(ctx context.Context, reset *model.PasswordReset) error {
+			reset.ID = int64(len(resets) + 1)
+			resets[reset.TokenHash] = reset
+			return nil
+		},
+		GetResetFunc: func(ctx context.Context, tokenHash string) (*model.PasswordReset, error) {
+			reset, ok := resets[tokenHash]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *reset
+			return &copied, nil
+		},
+		ResetPasswordFunc: func(ctx context.Context, reset *model.PasswordReset, passwordHash string, usedAt time.Time) error {
+			for _, r := range resets {
+				if r.UserID == reset.UserID && r.UsedAt == nil {
+					r.UsedAt = &usedAt
+				}
+			}
+			u.PasswordHash = passwordHash
+			return nil
+		},
+		AnonymizeFunc: func(ctx context.Context, userID int64, login string, deletedAt time.Time) error {
+			u.Login, u.PasswordHash = login, ""
+			return nil
+		},
+	}, u
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	st, u := newAccountStorage("password")
+	var revokedFor []int64
+	st.RevokeUserFunc = func(ctx context.Context, userID int64, revokedAt time.Time) error {
+		revokedFor = append(revokedFor, userID)
+		return nil
+	}
+	store := revocation.NewMemoryStore(time.Minute)
+	s := service.New(st, "secret", service.WithRevocation(store))
+
+	tests := []struct {
+		name        string
+		current     string
+		next        string
+		expectedErr error
+		field       string
+	}{
+		{name: "too short", current: "password", next: "12345", field: "new_password"},
+		{name: "same password", current: "password", next: "password", field: "new_password"},
+		{name: "wrong current password", current: "guess123", next: "new-password", expectedErr: model.ErrWrongPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ChangePassword(ctx, tt.current, tt.next, 1)
+			if tt.field != "" {
+				var fieldErr *model.FieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, tt.field, fieldErr.Field)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+	assert.Empty(t, revokedFor)
+
+	issuedAt := time.Now().Add(-time.Second)
+	require.NoError(t, s.ChangePassword(ctx, "password", "new-password", 1))
+
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("new-password")))
+	assert.Equal(t, []int64{1}, revokedFor, "refresh tokens are revoked")
+	revoked, err := store.IsRevoked(ctx, "jti", 1, issuedAt)
+	require.NoError(t, err)
+	assert.True(t, revoked, "access tokens issued before the change are revoked")
+}
+
+func TestService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	st, u := newAccountStorage("password")
+	st.RevokeUserFunc = func(ctx context.Context, userID int64, revokedAt time.Time) error {
+		return nil
+	}
+
+	err := service.New(st, "secret").RequestPasswordReset(ctx, "petr")
+	assert.ErrorIs(t, err, model.ErrPasswordResetDisabled)
+
+	notifier := &recordingNotifier{}
+	s := service.New(st, "secret", service.WithNotifier(notifier))
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "nobody"), "unknown logins are not revealed")
+	assert.Empty(t, notifier.messages)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "petr"))
+	require.Len(t, notifier.messages, 1)
+	msg := notifier.messages[0]
+	assert.Equal(t, int64(1), msg.UserID)
+	assert.Equal(t, "petr", msg.Login)
+
+	token := strings.TrimPrefix(strings.SplitN(msg.Body, "\n", 2)[0], "Use this token to reset your password: ")
+	require.NotEmpty(t, token)
+
+	var fieldErr *model.FieldError
+	require.ErrorAs(t, s.ResetPassword(ctx, token, "123"), &fieldErr)
+	assert.ErrorIs(t, s.ResetPassword(ctx, "unknown", "new-password"), model.ErrInvalidResetToken)
+
+	require.NoError(t, s.ResetPassword(ctx, token, "new-password"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("new-password")))
+
+	assert.ErrorIs(t, s.ResetPassword(ctx, token, "other-password"), model.ErrInvalidResetToken, "tokens are single use")
+
+	expired := service.New(st, "secret", service.WithNotifier(notifier), service.WithAuth(config.Auth{PasswordResetTTL: time.Nanosecond}))
+	require.NoError(t, expired.RequestPasswordReset(ctx, "petr"))
+	token = strings.TrimPrefix(strings.SplitN(notifier.messages[1].Body, "\n", 2)[0], "Use this token to reset your password: ")
+	time.Sleep(time.Millisecond)
+	assert.ErrorIs(t, expired.ResetPassword(ctx, token, "other-password"), model.ErrInvalidResetToken)
+}
+
+func TestService_DeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	st, u := newAccountStorage("password")
+	var revokedFor []int64
+	st.RevokeUserFunc = func(ctx context.Context, userID int64, revokedAt time.Time) error {
+		revokedFor = append(revokedFor, userID)
+		return nil
+	}
+	s := service.New(st, "secret")
+
+	assert.ErrorIs(t, s.DeleteAccount(ctx, "guess123", 1), model.ErrWrongPassword)
+	assert.ErrorIs(t, s.DeleteAccount(ctx, "password", 2), model.ErrUserNotFound)
+	assert.Empty(t, revokedFor)
+
+	require.NoError(t, s.DeleteAccount(ctx, "password", 1))
+	assert.True(t, strings.HasPrefix(u.Login, "deleted-"))
+	assert.Empty(t, u.PasswordHash)
+	assert.Equal(t, []int64{1}, revokedFor)
+}
+
+type recordingMailer struct {
+	messages []mail.Message
+	err      error
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+func verificationToken(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if token, ok := strings.CutPrefix(line, "use this token to confirm your email: "); ok {
+			return token
+		}
+	}
+	t.Fatalf("no verification token in %q", msg.Body)
+	return ""
+}
+
+func newEmailStorage() (*mockStorage, map[int64]*model.User) {
+	users := map[int64]*model.User{
+		2: {ID: 2, Login: "pavel", Email: "pavel@example.com"},
+	}
+	verifications := map[string]*model.EmailVerification{}
+
+	return &mockStorage{
+		CreateUserFunc: func(ctx context.Context, user *model.User) error {
+			user.ID = int64(len(users) + 1)
+			copied := *user
+			users[user.ID] = &copied
+			return nil
+		},
+		GetUserByIDFunc: func(ctx context.Context, id int64) (*model.User, error) {
+			u, ok := users[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *u
+			return &copied, nil
+		},
+		EmailInUseFunc: func(ctx context.Context, email string, exceptUserID int64) (bool, error) {
+			for _, u := range users {
+				if u.Email == email && u.ID != exceptUserID {
+					return true, nil
+				}
+			}
+			return false, nil
+		},
+		UpdateEmailFunc: func(ctx context.Context, userID int64, email string) error {
+			users[userID].Email, users[userID].EmailVerifiedAt = email, nil
+			return nil
+		},
+		CreateVerifyFunc: func(ctx context.Context, v *model.EmailVerification) error {
+			v.ID = int64(len(verifications) + 1)
+			verifications[v.TokenHash] = v
+			return nil
+		},
+		GetVerifyFunc: func(ctx context.Context, tokenHash string) (*model.EmailVerification, error) {
+			v, ok := verifications[tokenHash]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *v
+			return &copied, nil
+		},
+		VerifyEmailFunc: func(ctx context.Context, v *model.EmailVerification, verifiedAt time.Time) error {
+			for _, other := range verifications {
+				if other.UserID == v.UserID && other.UsedAt == nil {
+					other.UsedAt = &verifiedAt
+				}
+			}
+			u := users[v.UserID]
+			if u.Email != v.Email {
+				return sql.ErrNoRows
+			}
+			u.EmailVerifiedAt = &verifiedAt
+			return nil
+		},
+	}, users
+}
+
+func TestService_RegisterWithEmail(t *testing.T) {
+	ctx := context.Background()
+	st, users := newEmailStorage()
+
+	_, err := service.New(st, "secret").RegisterUser(ctx, &auth.RegistrationRequest{Login: "petr", Password: "password", Email: "petr@example.com"})
+	assert.ErrorIs(t, err, model.ErrEmailVerificationDisabled)
+
+	mailer := &recordingMailer{}
+	s := service.New(st, "secret", service.WithMailer(mailer))
+
+	tests := []struct {
+		name        string
+		email       string
+		expectedErr error
+		field       string
+	}{
+		{name: "invalid address", email: "petr at example.com", field: "email"},
+		{name: "display name is not an address", email: "Petr <petr@example.com>", field: "email"},
+		{name: "taken by another user", email: " Pavel@Example.com ", expectedErr: model.ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.RegisterUser(ctx, &auth.RegistrationRequest{Login: "petr", Password: "password", Email: tt.email})
+			if tt.field != "" {
+				var fieldErr *model.FieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, tt.field, fieldErr.Field)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+	assert.Empty(t, mailer.messages)
+
+	resp, err := s.RegisterUser(ctx, &auth.RegistrationRequest{Login: "petr", Password: "password", Email: "Petr@Example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "petr@example.com", resp.Email)
+	require.NotNil(t, resp.EmailVerificationSent)
+	assert.True(t, *resp.EmailVerificationSent)
+	assert.Equal(t, "petr@example.com", users[resp.ID].Email)
+	assert.Nil(t, users[resp.ID].EmailVerifiedAt)
+
+	require.Len(t, mailer.messages, 1)
+	assert.Equal(t, "petr@example.com", mailer.messages[0].To)
+	token := verificationToken(t, mailer.messages[0])
+
+	assert.ErrorIs(t, s.VerifyEmail(ctx, "unknown"), model.ErrInvalidVerificationToken)
+	require.NoError(t, s.VerifyEmail(ctx, token))
+	assert.NotNil(t, users[resp.ID].EmailVerifiedAt)
+	assert.ErrorIs(t, s.VerifyEmail(ctx, token), model.ErrInvalidVerificationToken, "tokens are single use")
+
+	resp, err = s.RegisterUser(ctx, &auth.RegistrationRequest{Login: "ivan", Password: "password"})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Email)
+	assert.Nil(t, resp.EmailVerificationSent)
+	assert.Len(t, mailer.messages, 1, "no mail without an email")
+
+	mailer.err = errors.New("smtp unavailable")
+	resp, err = s.RegisterUser(ctx, &auth.RegistrationRequest{Login: "anna", Password: "password", Email: "anna@example.com"})
+	require.NoError(t, err, "the account is created even if the mail is not sent")
+	assert.Equal(t, "anna@example.com", users[resp.ID].Email)
+	require.NotNil(t, resp.EmailVerificationSent)
+	assert.False(t, *resp.EmailVerificationSent)
+}
+
+func TestService_SetEmail(t *testing.T) {
+	ctx := context.Background()
+	st, users := newEmailStorage()
+	users[1] = &model.User{ID: 1, Login: "petr"}
+
+	assert.ErrorIs(t, service.New(st, "secret").SetEmail(ctx, "petr@example.com", 1), model.ErrEmailVerificationDisabled)
+
+	mailer := &recordingMailer{}
+	s := service.New(st, "secret", service.WithMailer(mailer))
+
+	assert.ErrorIs(t, s.SetEmail(ctx, "pavel@example.com", 1), model.ErrEmailTaken)
+	assert.ErrorIs(t, s.SetEmail(ctx, "petr@example.com", 9), model.ErrUserNotFound)
+
+	require.NoError(t, s.SetEmail(ctx, "petr@example.com", 1))
+	require.NoError(t, s.SetEmail(ctx, "petr@example.com", 1), "resending keeps the address")
+	require.Len(t, mailer.messages, 2)
+	first, second := verificationToken(t, mailer.messages[0]), verificationToken(t, mailer.messages[1])
+
+	require.NoError(t, s.VerifyEmail(ctx, second))
+	assert.ErrorIs(t, s.VerifyEmail(ctx, first), model.ErrInvalidVerificationToken, "older tokens are used up")
+	assert.ErrorIs(t, s.SetEmail(ctx, "petr@example.com", 1), model.ErrEmailAlreadyVerified)
+
+	require.NoError(t, s.SetEmail(ctx, "petr@example.org", 1))
+	assert.Equal(t, "petr@example.org", users[1].Email)
+	assert.Nil(t, users[1].EmailVerifiedAt, "a new address must be verified again")
+
+	stale := verificationToken(t, mailer.messages[2])
+	require.NoError(t, s.SetEmail(ctx, "petr@example.net", 1))
+	assert.ErrorIs(t, s.VerifyEmail(ctx, stale), model.ErrInvalidVerificationToken, "token for a replaced address")
+
+	expired := service.New(st, "secret", service.WithMailer(mailer), service.WithAuth(config.Auth{EmailVerificationTTL: time.Nanosecond}))
+	require.NoError(t, expired.SetEmail(ctx, "petr@example.net", 1))
+	time.Sleep(time.Millisecond)
+	assert.ErrorIs(t, expired.VerifyEmail(ctx, verificationToken(t, mailer.messages[len(mailer.messages)-1])), model.ErrInvalidVerificationToken)
+}
+
+func TestService_CreateAdRequiresVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	st, users := newEmailStorage()
+	verifiedAt := time.Now()
+	users[1] = &model.User{ID: 1, Login: "petr", Email: "petr@example.com", EmailVerifiedAt: &verifiedAt}
+	st.CreateAdFunc = func(ctx context.Context, ad *model.Ad) error {
+		ad.ID = 1
+		return nil
+	}
+	st.GetImageByURLFunc = func(ctx context.Context, url string) (*model.Image, error) {
+		return &model.Image{URL: url, OwnerID: 1}, nil
+	}
+
+	req := ad.CreateRequest{Title: "Bike", Description: "Good bike", ImageURL: "http://localhost/static/images/bike.jpg", Price: 100}
+
+	_, err := service.New(st, "secret").CreateAd(ctx, req, 2)
+	assert.NoError(t, err, "policy is off by default")
+
+	s := service.New(st, "secret", service.WithAuth(config.Auth{RequireVerifiedEmail: true}))
+	_, err = s.CreateAd(ctx, req, 2)
+	assert.ErrorIs(t, err, model.ErrEmailNotVerified)
+
+	_, err = s.CreateAd(ctx, req, 1)
+	assert.NoError(t, err)
+}
+
+func TestService_Reviews(t *testing.T) {
+	ctx := context.Background()
+	ads := map[int64]*model.AdWithAuthor{
+		1: {ID: 1, Title: "Bike", AuthorID: 42, Status: model.AdStatusPublished},
+		2: {ID: 2, Title: "Sofa", AuthorID: 42, Status: model.AdStatusArchived},
+	}
+	st := newThreadStorage(ads)
+	st.GetUserByIDFunc = func(ctx context.Context, id int64) (*model.User, error) {
+		return &model.User{ID: id, Login: "user" + strconv.FormatInt(id, 10)}, nil
+	}
+
+	var reviews []model.Review
+	profile := &model.Profile{ID: 42, Login: "petr"}
+	st.CreateReviewFunc = func(ctx context.Context, review *model.Review) error {
+		for _, r := range reviews {
+			if r.AdID == review.AdID && r.ReviewerID == review.ReviewerID {
+				return sql.ErrNoRows
+			}
+		}
+		review.ID = int64(len(reviews) + 1)
+		reviews = append(reviews, *review)
+		profile.ReviewsCount++
+		return nil
+	}
+	st.GetProfileFunc = func(ctx context.Context, login string) (*model.Profile, error) {
+		if login != profile.Login {
+			return nil, sql.ErrNoRows
+		}
+		return profile, nil
+	}
+	st.GetReviewsFunc = func(ctx context.Context, sellerID int64, offset, limit int) ([]model.Review, error) {
+		return reviews, nil
+	}
+	s := service.New(st, "secret")
+
+	_, _, err := s.StartThread(ctx, 1, "Is it still available?", 5)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		adID        int64
+		rating      int
+		text        string
+		userID      int64
+		expectedErr error
+		field       string
+	}{
+		{name: "rating out of range", adID: 1, rating: 6, userID: 5, field: "rating"},
+		{name: "text too long", adID: 1, rating: 5, text: strings.Repeat("a", 1001), userID: 5, field: "text"},
+		{name: "missing ad", adID: 99, rating: 5, userID: 5, expectedErr: model.ErrAdNotFound},
+		{name: "own ad", adID: 1, rating: 5, userID: 42, expectedErr: model.ErrReviewOwnAd},
+		{name: "no conversation about the ad", adID: 2, rating: 1, userID: 5, expectedErr: model.ErrReviewNotAllowed},
+		{name: "no conversation at all", adID: 1, rating: 1, userID: 6, expectedErr: model.ErrReviewNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.CreateReview(ctx, tt.adID, tt.rating, tt.text, tt.userID)
+			if tt.field != "" {
+				var fieldErr *model.FieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, tt.field, fieldErr.Field)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+
+	review, err := s.CreateReview(ctx, 1, 5, "  Fast and friendly  ", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), review.SellerID)
+	assert.Equal(t, "Bike", review.AdTitle)
+	assert.Equal(t, "user5", review.ReviewerLogin)
+	assert.Equal(t, "Fast and friendly", review.Text)
+
+	_, err = s.CreateReview(ctx, 1, 1, "Changed my mind", 5)
+	assert.ErrorIs(t, err, model.ErrDuplicateReview)
+
+	list, err := s.GetSellerReviews(ctx, "petr", 1, 20)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), list.Total)
+	require.Len(t, list.Items, 1)
+
+	_, err = s.GetSellerReviews(ctx, "nobody", 1, 20)
+	assert.ErrorIs(t, err, model.ErrUserNotFound)
+}
+
+func TestService_Profile(t *testing.T) {
+	ctx := context.Background()
+	profiles := map[int64]*model.Profile{42: {ID: 42, Login: "petr", Role: model.RoleUser, About: "Bikes"}}
+	mock := &mockStorage{
+		GetProfileFunc: func(ctx context.Context, login string) (*model.Profile, error) {
+			for _, p := range profiles {
+				if p.Login == login {
+					copied := *p
+					return &copied, nil
+				}
+			}
+			return nil, sql.ErrNoRows
+		},
+		GetProfileByIDFunc: func(ctx context.Context, id int64) (*model.Profile, error) {
+			p, ok := profiles[id]
+			if !ok {
+				return nil, sql.ErrNoRows
+			}
+			copied := *p
+			return &copied, nil
+		},
+		UpdateProfileFunc: func(ctx context.Context, profile *model.Profile) error {
+			copied := *profile
+			profiles[profile.ID] = &copied
+			return nil
+		},
+		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
+			assert.Equal(t, int64(42), req.AuthorID)
+			return []*model.AdWithAuthor{{ID: 1, AuthorID: 42}}, nil
+		},
+		CountAdsFunc: func(ctx context.Context, req *ad.ListRequest) (int64, error) {
+			return 1, nil
+		},
+		GetRateFunc: func(ctx context.Context, currency string) (*model.ExchangeRate, error) {
+			return nil, sql.ErrNoRows
+		},
+	}
+	s := service.New(mock, "secret")
+
+	t.Run("update validation", func(t *testing.T) {
+		long := strings.Repeat("a", 51)
+		badURL := "not a url"
+		tests := []struct {
+			name        string
+			req         user.UpdateProfileRequest
+			expectedErr error
+			field       string
+		}{
+			{name: "empty update", expectedErr: model.ErrEmptyUpdate},
+			{name: "long display name", req: user.UpdateProfileRequest{DisplayName: &long}, field: "display_name"},
+			{name: "invalid avatar", req: user.UpdateProfileRequest{AvatarURL: &badURL}, field: "avatar_url"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := s.UpdateProfile(ctx, tt.req, 42)
+				if tt.field != "" {
+					var fieldErr *model.FieldError
+					require.ErrorAs(t, err, &fieldErr)
+					assert.Equal(t, tt.field, fieldErr.Field)
+					return
+				}
+				assert.ErrorIs(t, err, tt.expectedErr)
+			})
+		}
+	})
+
+	name := "  Petr \u200b  Ivanov "
+	location := "Kazan"
+	updated, err := s.UpdateProfile(ctx, user.UpdateProfileRequest{DisplayName: &name, Location: &location}, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "Petr Ivanov", updated.DisplayName)
+	assert.Equal(t, "Kazan", updated.Location)
+	assert.Equal(t, "Bikes", updated.About, "fields missing from the request are kept")
+
+	me, err := s.GetMe(ctx, 42)
+	require.NoError(t, err)
+	assert.Equal(t, "Petr Ivanov", me.DisplayName)
+
+	_, err = s.GetMe(ctx, 7)
+	assert.ErrorIs(t, err, model.ErrUserNotFound)
+
+	req := ad.ListRequest{Page: 1, PageSize: 10}
+	list, err := s.GetUserAds(ctx, "petr", &req, 0)
+	require.NoError(t, err)
+	assert.Len(t, list.Ads, 1)
+
+	_, err = s.GetUserAds(ctx, "nobody", &ad.ListRequest{Page: 1, PageSize: 10}, 0)
+	assert.ErrorIs(t, err, model.ErrUserNotFound)
+}
+
+type publishedEvent struct {
+	userID    int64
+	eventType string
+	payload   any
+}
+
+type recordingPublisher struct {
+	events []publishedEvent
+}
+
+func (p *recordingPublisher) Publish(userID int64, eventType string, payload any) {
+	p.events = append(p.events, publishedEvent{userID: userID, eventType: eventType, payload: payload})
+}
+
+func TestService_Events(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleUser, 2: model.RoleModerator}
+
+	t.Run("chat messages reach both participants", func(t *testing.T) {
+		pub := &recordingPublisher{}
+		ads := map[int64]*model.AdWithAuthor{1: {ID: 1, AuthorID: 42, Status: model.AdStatusPublished}}
+		s := service.New(newThreadStorage(ads), "secret", service.WithEvents(pub))
+
+		thread, _, err := s.StartThread(ctx, 1, "Hi", 5)
+		require.NoError(t, err)
+
+		require.Len(t, pub.events, 2)
+		assert.Equal(t, []int64{5, 42}, []int64{pub.events[0].userID, pub.events[1].userID})
+		assert.Equal(t, events.TypeMessage, pub.events[0].eventType)
+		payload := pub.events[0].payload.(events.MessagePayload)
+		assert.Equal(t, thread.ID, payload.ThreadID)
+		assert.Equal(t, int64(1), payload.AdID)
+		assert.Equal(t, "Hi", payload.Body)
+	})
+
+	t.Run("moderation decisions reach the author", func(t *testing.T) {
+		pub := &recordingPublisher{}
+		ads := map[int64]*model.AdWithAuthor{
+			1: {ID: 1, Title: "Phone", AuthorID: 1, Status: model.AdStatusPending},
+			2: {ID: 2, Title: "Book", AuthorID: 1, Status: model.AdStatusPublished},
+		}
+		s := service.New(newModerationStorage(ads, roles), "secret", service.WithEvents(pub))
+
+		_, err := s.RejectAd(ctx, 1, "Blurry photos", 2)
+		require.NoError(t, err)
+		_, err = s.ArchiveAd(ctx, 2, 1)
+		require.NoError(t, err)
+
+		require.Len(t, pub.events, 1, "authors are not notified about their own changes")
+		assert.Equal(t, int64(1), pub.events[0].userID)
+		assert.Equal(t, events.AdStatusPayload{AdID: 1, Title: "Phone", Status: model.AdStatusRejected, Reason: "Blurry photos"}, pub.events[0].payload)
+	})
+
+	t.Run("price drops reach users who favorited the ad", func(t *testing.T) {
+		pub := &recordingPublisher{}
+		ads := map[int64]*model.AdWithAuthor{
+			1: {ID: 1, Title: "Bike", AuthorID: 1, Status: model.AdStatusPublished, Price: mustAmount("100"), Currency: "RUB"},
+		}
+		st := newModerationStorage(ads, roles)
+		st.FavoriteUsersFunc = func(ctx context.Context, adID int64) ([]int64, error) {
+			return []int64{5, 6}, nil
+		}
+		s := service.New(st, "secret", service.WithEvents(pub))
+
+		higher := mustAmount("120")
+		_, err := s.UpdateAd(ctx, 1, ad.UpdateRequest{Price: &higher}, 1)
+		require.NoError(t, err)
+		assert.Empty(t, pub.events)
+
+		lower := mustAmount("80")
+		_, err = s.UpdateAd(ctx, 1, ad.UpdateRequest{Price: &lower}, 1)
+		require.NoError(t, err)
+
+		require.Len(t, pub.events, 2)
+		assert.Equal(t, int64(5), pub.events[0].userID)
+		assert.Equal(t, int64(6), pub.events[1].userID)
+		assert.Equal(t, events.PriceDropPayload{
+			AdID:     1,
+			Title:    "Bike",
+			OldPrice: mustAmount("100"),
+			NewPrice: lower,
+			Currency: "RUB",
+		}, pub.events[0].payload)
+	})
+}
+
+func TestService_ReportAd(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleUser, 2: model.RoleModerator}
+	ads := map[int64]*model.AdWithAuthor{
+		1: {ID: 1, AuthorID: 1, Status: model.AdStatusPublished},
+	}
+
+	type key struct{ adID, reporterID int64 }
+	open := map[key]bool{}
+	mock := newModerationStorage(ads, roles)
+	mock.CreateReportFunc = func(ctx context.Context, report *model.Report) error {
+		k := key{report.AdID, report.ReporterID}
+		if open[k] {
+			return sql.ErrNoRows
+		}
+		open[k] = true
+		report.ID = int64(len(open))
+		return nil
+	}
+	mock.CountOpenFunc = func(ctx context.Context, adID int64) (int64, error) {
+		var n int64
+		for k := range open {
+			if k.adID == adID {
+				n++
+			}
+		}
+		return n, nil
+	}
+	mock.HideAdFunc = func(ctx context.Context, adID int64, hiddenAt time.Time) error {
+		ads[adID].HiddenAt = &hiddenAt
+		return nil
+	}
+
+	s := service.New(mock, "secret", service.WithReports(config.Reports{HideThreshold: 2}))
+
+	_, err := s.ReportAd(ctx, 1, "ugly", "", 10)
+	var fieldErr *model.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+
+	_, err = s.ReportAd(ctx, 1, "other", "  ", 10)
+	assert.ErrorAs(t, err, &fieldErr)
+
+	_, err = s.ReportAd(ctx, 1, "scam", "", 1)
+	assert.ErrorIs(t, err, model.ErrReportOwnAd)
+
+	report, err := s.ReportAd(ctx, 1, "scam", " asks for prepayment ", 10)
+	require.NoError(t, err)
+	assert.Equal(t, model.ReportStatusOpen, report.Status)
+	assert.Equal(t, "asks for prepayment", report.Comment)
+
+	_, err = s.ReportAd(ctx, 1, "spam", "", 10)
+	assert.ErrorIs(t, err, model.ErrDuplicateReport)
+	assert.Nil(t, ads[1].HiddenAt)
+
+	_, err = s.ReportAd(ctx, 1, "spam", "", 11)
+	require.NoError(t, err)
+	assert.NotNil(t, ads[1].HiddenAt, "ad is hidden once the threshold is reached")
+
+	_, err = s.GetVisibleAd(ctx, 1, 12)
+	assert.ErrorIs(t, err, model.ErrAdNotFound)
+	_, err = s.ReportAd(ctx, 1, "spam", "", 12)
+	assert.ErrorIs(t, err, model.ErrAdNotFound)
+
+	assert.ErrorIs(t, s.DismissReports(ctx, 1, 10), model.ErrNotModerator)
+	require.NoError(t, s.DismissReports(ctx, 1, 2))
+	assert.Nil(t, ads[1].HiddenAt)
+
+	_, err = s.GetVisibleAd(ctx, 1, 12)
+	assert.NoError(t, err)
+}
+
+func TestService_ModerationWorkflow(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleUser, 2: model.RoleModerator, 3: model.RoleUser}
+	create := func(s *service.Service, categoryID int64) *model.Ad {
+		created, err := s.CreateAd(ctx, ad.CreateRequest{
+			Title:       "Phone",
+			Description: "Almost new",
+			ImageURL:    "http://example.com/phone.jpg",
+			Price:       mustAmount("100"),
+			CategoryID:  categoryID,
+		}, 1)
+		require.NoError(t, err)
+		return created
+	}
+
+	t.Run("initial status depends on category", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		s := service.New(newModerationStorage(ads, roles), "secret",
+			service.WithModeration(config.Moderation{Categories: []string{"electronics"}}))
+
+		assert.Equal(t, model.AdStatusPending, create(s, 1).Status)
+		assert.Equal(t, model.AdStatusPending, create(s, 4).Status, "subcategories inherit pre-moderation")
+		assert.Equal(t, model.AdStatusPublished, create(s, 2).Status)
+		assert.Equal(t, model.AdStatusPublished, create(s, 0).Status)
+	})
+
+	t.Run("approve and reject", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		s := service.New(newModerationStorage(ads, roles), "secret",
+			service.WithModeration(config.Moderation{Categories: []string{"electronics"}}))
+		created := create(s, 1)
+
+		_, err := s.GetVisibleAd(ctx, created.ID, 3)
+		assert.ErrorIs(t, err, model.ErrAdNotFound, "pending ads are hidden from other users")
+		_, err = s.GetVisibleAd(ctx, created.ID, 1)
+		assert.NoError(t, err)
+
+		_, err = s.ApproveAd(ctx, created.ID, 1)
+		assert.ErrorIs(t, err, model.ErrNotModerator)
+
+		_, err = s.RejectAd(ctx, created.ID, "   ", 2)
+		var fieldErr *model.FieldError
+		assert.ErrorAs(t, err, &fieldErr)
+
+		rejected, err := s.RejectAd(ctx, created.ID, "prohibited item", 2)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusRejected, rejected.Status)
+		assert.Equal(t, "prohibited item", rejected.RejectionReason)
+
+		_, err = s.ApproveAd(ctx, created.ID, 2)
+		assert.ErrorIs(t, err, model.ErrInvalidAdTransition)
+
+		title := "Phone, fixed"
+		updated, err := s.UpdateAd(ctx, created.ID, ad.UpdateRequest{Title: &title}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusPending, updated.Status, "author edits resubmit a rejected ad")
+		assert.Empty(t, updated.RejectionReason)
+
+		approved, err := s.ApproveAd(ctx, created.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusPublished, approved.Status)
+
+		_, err = s.GetVisibleAd(ctx, created.ID, 3)
+		assert.NoError(t, err)
+
+		updated, err = s.UpdateAd(ctx, created.ID, ad.UpdateRequest{Title: &title}, 2)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusPublished, updated.Status, "moderator edits keep the status")
+
+		updated, err = s.UpdateAd(ctx, created.ID, ad.UpdateRequest{Title: &title}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusPending, updated.Status, "author edits in pre-moderated categories are reviewed again")
+	})
+
+	t.Run("concurrent moderation wins over an edit", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		st := newModerationStorage(ads, roles)
+		s := service.New(st, "secret", service.WithModeration(config.Moderation{Categories: []string{"electronics"}}))
+		created := create(s, 1)
+
+		updateAd := st.UpdateAdFunc
+		st.UpdateAdFunc = func(ctx context.Context, a *model.Ad, change *model.AdStatusChange) error {
+			_, err := s.RejectAd(ctx, a.ID, "prohibited item", 2)
+			require.NoError(t, err)
+			return updateAd(ctx, a, change)
+		}
+
+		title := "Phone, fixed"
+		_, err := s.UpdateAd(ctx, created.ID, ad.UpdateRequest{Title: &title}, 1)
+		assert.ErrorIs(t, err, model.ErrAdStatusChanged)
+		assert.Equal(t, model.AdStatusRejected, ads[created.ID].Status)
+		assert.Equal(t, "prohibited item", ads[created.ID].RejectionReason)
+		assert.Equal(t, "Phone", ads[created.ID].Title)
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		s := service.New(newModerationStorage(ads, roles), "secret")
+		created := create(s, 2)
+
+		_, err := s.ArchiveAd(ctx, created.ID, 3)
+		assert.ErrorIs(t, err, model.ErrNotAdAuthor)
+
+		archived, err := s.ArchiveAd(ctx, created.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusArchived, archived.Status)
+
+		_, err = s.ApproveAd(ctx, created.ID, 2)
+		assert.ErrorIs(t, err, model.ErrInvalidAdTransition)
+		_, err = s.ArchiveAd(ctx, created.ID, 1)
+		assert.ErrorIs(t, err, model.ErrInvalidAdTransition)
+	})
+
+	t.Run("queue requires moderator", func(t *testing.T) {
+		mock := newModerationStorage(map[int64]*model.AdWithAuthor{}, roles)
+		mock.GetByStatusFunc = func(ctx context.Context, status string, offset, limit int) ([]*model.AdWithAuthor, error) {
+			assert.Equal(t, model.AdStatusPending, status)
+			assert.Equal(t, 20, offset)
+			assert.Equal(t, 10, limit)
+			return []*model.AdWithAuthor{{ID: 1, Status: status}}, nil
+		}
+		mock.CountByStatusFunc = func(ctx context.Context, status string) (int64, error) {
+			return 21, nil
+		}
+		s := service.New(mock, "secret")
+
+		_, err := s.GetModerationQueue(ctx, 3, 10, 1)
+		assert.ErrorIs(t, err, model.ErrNotModerator)
+
+		list, err := s.GetModerationQueue(ctx, 3, 10, 2)
+		require.NoError(t, err)
+		assert.Len(t, list.Ads, 1)
+		assert.Equal(t, int64(21), list.Total)
+	})
+}
+
+func TestService_ContentPolicy(t *testing.T) {
+	ctx := context.Background()
+	roles := map[int64]string{1: model.RoleUser, 2: model.RoleModerator, 3: model.RoleAdmin}
+
+	policy, err := contentpolicy.New([]contentpolicy.Rule{
+		{Name: "phone-number", Patterns: []string{`\+?\p{Nd}(?:[\s\-().]{0,3}\p{Nd}){9,}`}, Action: contentpolicy.ActionReject},
+		{Name: "messenger-mention", Terms: []string{"telegram", "whatsapp"}, Action: contentpolicy.ActionModerate},
+		{Name: "external-link", Patterns: []string{`https?://\S+`}, Action: contentpolicy.ActionMask, Fields: []string{contentpolicy.FieldDescription}},
+	})
+	require.NoError(t, err)
+
+	newService := func(ads map[int64]*model.AdWithAuthor) *service.Service {
+		return service.New(newModerationStorage(ads, roles), "secret",
+			service.WithAdmins(3),
+			service.WithContentPolicy(policy))
+	}
+	create := func(s *service.Service, title, description string) (*model.Ad, error) {
+		return s.CreateAd(ctx, ad.CreateRequest{
+			Title:       title,
+			Description: description,
+			ImageURL:    "http://example.com/bike.jpg",
+			Price:       mustAmount("100"),
+		}, 1)
+	}
+
+	t.Run("reject reports the matched rule", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		_, err := create(newService(ads), "Bike", "Call me: 8 (912) 345-67-89")
+
+		var fieldErr *model.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "description", fieldErr.Field)
+		assert.Equal(t, "phone-number", fieldErr.Rule)
+		assert.Empty(t, ads)
+	})
+
+	t.Run("moderate sends the ad to the queue", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		created, err := create(newService(ads), "Bike", "Write me on WhatsApp")
+		require.NoError(t, err)
+
+		assert.Equal(t, model.AdStatusPending, created.Status)
+		assert.Equal(t, []string{"messenger-mention"}, created.PolicyMatches)
+	})
+
+	t.Run("mask rewrites the text", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		created, err := create(newService(ads), "Bike", "Photos: https://example.com/a")
+		require.NoError(t, err)
+
+		assert.Equal(t, model.AdStatusPublished, created.Status)
+		assert.Equal(t, "Photos: *********************", created.Description)
+		assert.Empty(t, created.PolicyMatches)
+	})
+
+	t.Run("author edit is checked again", func(t *testing.T) {
+		ads := map[int64]*model.AdWithAuthor{}
+		s := newService(ads)
+		created, err := create(s, "Bike", "Almost new")
+		require.NoError(t, err)
+
+		phone := "Call +79123456789"
+		_, err = s.UpdateAd(ctx, created.ID, ad.UpdateRequest{Description: &phone}, 1)
+		var fieldErr *model.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "phone-number", fieldErr.Rule)
+		assert.Equal(t, "Almost new", ads[created.ID].Description)
+
+		mention := "Ask in telegram"
+		updated, err := s.UpdateAd(ctx, created.ID, ad.UpdateRequest{Description: &mention}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusPending, updated.Status)
+		assert.Equal(t, []string{"messenger-mention"}, ads[created.ID].PolicyMatches)
+	})
+
+	t.Run("reload requires an admin", func(t *testing.T) {
+		s := newService(map[int64]*model.AdWithAuthor{})
+
+		_, err := s.ReloadContentPolicy(ctx, 2)
+		assert.ErrorIs(t, err, model.ErrNotAdmin)
+
+		rules, err := s.ReloadContentPolicy(ctx, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, rules)
+
+		_, err = service.New(&mockStorage{}, "secret", service.WithAdmins(3)).ReloadContentPolicy(ctx, 3)
+		assert.ErrorIs(t, err, model.ErrNoContentPolicy)
+	})
+}
+
+func TestService_CreateAd(t *testing.T) {
+	validReq := ad.CreateRequest{
+		Title:       "Valid Title",
+		Description: "Valid description",
+		ImageURL:    "http://example.com/image.jpg",
+		Price:       100,
+	}
+
+	tests := []struct {
+		name        string
+		req         ad.CreateRequest
+		userID      int64
+		mockSetup   func(*mockStorage)
+		expectedErr string
+	}{
+		{
+			name:   "successful creation",
+			req:    validReq,
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.CreateAdFunc = func(ctx context.Context, ad *model.Ad) error {
+					ad.ID = 1
+					return nil
+				}
+			},
+		},
+		{
+			name: "invalid title characters",
+			req: ad.CreateRequest{
+				Title:       "Invalid @#$ Title",
+				Description: validReq.Description,
+				ImageURL:    validReq.ImageURL,
+				Price:       validReq.Price,
+			},
+			userID:      1,
+			expectedErr: "title: character '@' is not allowed",
+		},
+		{
+			name: "unknown category",
+			req: ad.CreateRequest{
+				Title:       validReq.Title,
+				Description: validReq.Description,
+				ImageURL:    validReq.ImageURL,
+				Price:       validReq.Price,
+				CategoryID:  99,
+			},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetCategoryFunc = func(ctx context.Context, id int64) (*model.Category, error) {
+					return nil, sql.ErrNoRows
+				}
+			},
+			expectedErr: "category not found",
+		},
+		{
+			name: "invalid price",
+			req: ad.CreateRequest{
+				Title:       validReq.Title,
+				Description: validReq.Description,
+				ImageURL:    validReq.ImageURL,
+				Price:       0,
+			},
+			userID:      1,
+			expectedErr: "price must be positive",
+		},
+		{
+			name: "price over the limit",
+			req: ad.CreateRequest{
+				Title:       validReq.Title,
+				Description: validReq.Description,
+				ImageURL:    validReq.ImageURL,
+				Price:       money.MaxAmount + 1,
+			},
+			userID:      1,
+			expectedErr: "price: amount must not exceed 99999999.99",
+		},
+		{
+			name: "unsupported currency",
+			req: ad.CreateRequest{
+				Title:       validReq.Title,
+				Description: validReq.Description,
+				ImageURL:    validReq.ImageURL,
+				Price:       validReq.Price,
+				Currency:    "JPY",
+			},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetRateFunc = func(ctx context.Context, currency string) (*model.ExchangeRate, error) {
+					return nil, sql.ErrNoRows
+				}
+			},
+			expectedErr: "unsupported currency: JPY",
+		},
+		{
+			name:   "storage error",
+			req:    validReq,
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.CreateAdFunc = func(ctx context.Context, ad *model.Ad) error {
+					return errors.New("storage error")
+				}
+			},
+			expectedErr: "storage error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockStorage{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mock)
+			}
+
+			s := service.New(mock, "secret")
+			result, err := s.CreateAd(context.Background(), tt.req, tt.userID)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), result.ID)
+				assert.Equal(t, tt.req.Title, result.Title)
+				assert.Equal(t, tt.userID, result.AuthorID)
+			}
+		})
+	}
+}
+
+func TestService_UpdateAd(t *testing.T) {
+	existing := &model.AdWithAuthor{
+		ID:          7,
+		Title:       "Old Title",
+		Description: "Old description",
+		ImageURL:    "http://example.com/old.jpg",
+		Price:       100,
+		AuthorID:    1,
+		AuthorLogin: "owner",
+	}
+	newTitle := "New Title"
+	badTitle := "Bad @ Title"
+	blank := " \u200b "
+	newPrice := mustAmount("150")
+
+	tests := []struct {
+		name        string
+		req         ad.UpdateRequest
+		userID      int64
+		mockSetup   func(*mockStorage)
+		expectedErr string
+	}{
+		{
+			name:   "successful update",
+			req:    ad.UpdateRequest{Title: &newTitle, Price: &newPrice},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+				m.UpdateAdFunc = func(ctx context.Context, ad *model.Ad, change *model.AdStatusChange) error {
+					assert.Equal(t, newTitle, ad.Title)
+					assert.Equal(t, newPrice, ad.Price)
+					assert.Equal(t, existing.Description, ad.Description)
+					assert.False(t, ad.UpdatedAt.IsZero())
+					return nil
+				}
+			},
+		},
+		{
+			name:        "empty update",
+			req:         ad.UpdateRequest{},
+			userID:      1,
+			expectedErr: "nothing to update",
+		},
+		{
+			name:   "ad not found",
+			req:    ad.UpdateRequest{Title: &newTitle},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return nil, sql.ErrNoRows
+				}
+			},
+			expectedErr: "ad not found",
+		},
+		{
+			name:   "not the author",
+			req:    ad.UpdateRequest{Title: &newTitle},
+			userID: 2,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+			},
+			expectedErr: "only the author can modify this ad",
+		},
+		{
+			name:   "invalid title characters",
+			req:    ad.UpdateRequest{Title: &badTitle},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+			},
+			expectedErr: "title: character '@' is not allowed",
+		},
+		{
+			name:   "blank title",
+			req:    ad.UpdateRequest{Title: &blank},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+			},
+			expectedErr: "title: must not be empty",
+		},
+		{
+			name:   "blank description",
+			req:    ad.UpdateRequest{Description: &blank},
+			userID: 1,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+			},
+			expectedErr: "description: must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockStorage{}
+			if tt.mockSetup != nil {
+				tt.mockSetup(mock)
+			}
+
+			s := service.New(mock, "secret")
+			result, err := s.UpdateAd(context.Background(), existing.ID, tt.req, tt.userID)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, existing.ID, result.ID)
+				assert.Equal(t, newTitle, result.Title)
+			}
+		})
+	}
+}
+
+func TestService_DeleteAd(t *testing.T) {
+	existing := &model.AdWithAuthor{ID: 7, AuthorID: 1}
 
 	tests := []struct {
 		name        string
-		req         ad.CreateRequest
 		userID      int64
 		mockSetup   func(*mockStorage)
 		expectedErr string
 	}{
 		{
-			name:   "successful creation",
-			req:    validReq,
+			name:   "successful delete",
 			userID: 1,
 			mockSetup: func(m *mockStorage) {
-				m.CreateAdFunc = func(ctx context.Context, ad *model.Ad) error {
-					ad.ID = 1
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+				m.DeleteAdFunc = func(ctx context.Context, id, authorID int64) error {
+					assert.Equal(t, existing.ID, id)
+					assert.Equal(t, int64(1), authorID)
 					return nil
 				}
 			},
 		},
 		{
-			name: "invalid title characters",
-			req: ad.CreateRequest{
-				Title:       "Invalid @#$ Title",
-				Description: validReq.Description,
-				ImageURL:    validReq.ImageURL,
-				Price:       validReq.Price,
+			name:   "not the author",
+			userID: 2,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
 			},
-			userID:      1,
-			expectedErr: "invalid title characters",
+			expectedErr: "only the author can modify this ad",
 		},
 		{
-			name: "invalid price",
-			req: ad.CreateRequest{
-				Title:       validReq.Title,
-				Description: validReq.Description,
-				ImageURL:    validReq.ImageURL,
-				Price:       0,
+			name:   "moderator deletes someone else's ad",
+			userID: 3,
+			mockSetup: func(m *mockStorage) {
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return existing, nil
+				}
+				m.GetUserByIDFunc = func(ctx context.Context, id int64) (*model.User, error) {
+					return &model.User{ID: id, Role: model.RoleModerator}, nil
+				}
+				m.DeleteAdFunc = func(ctx context.Context, id, authorID int64) error {
+					assert.Equal(t, existing.AuthorID, authorID)
+					return nil
+				}
 			},
-			userID:      1,
-			expectedErr: "price must be positive",
 		},
 		{
-			name:   "storage error",
-			req:    validReq,
+			name:   "ad not found",
 			userID: 1,
 			mockSetup: func(m *mockStorage) {
-				m.CreateAdFunc = func(ctx context.Context, ad *model.Ad) error {
-					return errors.New("storage error")
+				m.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+					return nil, sql.ErrNoRows
 				}
 			},
-			expectedErr: "storage error",
+			expectedErr: "ad not found",
 		},
 	}
 
@@ -265,16 +2429,12 @@ func TestService_CreateAd(t *testing.T) {
 			}
 
 			s := service.New(mock, "secret")
-			result, err := s.CreateAd(context.Background(), tt.req, tt.userID)
+			err := s.DeleteAd(context.Background(), existing.ID, tt.userID)
 
 			if tt.expectedErr != "" {
 				assert.EqualError(t, err, tt.expectedErr)
-				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, int64(1), result.ID)
-				assert.Equal(t, tt.req.Title, result.Title)
-				assert.Equal(t, tt.userID, result.AuthorID)
 			}
 		})
 	}
@@ -311,6 +2471,7 @@ func TestService_GetAds(t *testing.T) {
 		userID        int64
 		mockSetup     func(*mockStorage)
 		expectedAds   []*model.AdWithAuthor
+		expectedTotal int64
 		expectedError string
 	}{
 		{
@@ -323,29 +2484,39 @@ func TestService_GetAds(t *testing.T) {
 			mockSetup: func(m *mockStorage) {
 				m.GetAdsFunc = func(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
 					assert.Equal(t, 1, offset)
-					assert.Equal(t, 1, limit)
+					assert.Equal(t, 2, limit)
 					return []*model.AdWithAuthor{sampleAds[1]}, nil
 				}
+				m.CountAdsFunc = func(ctx context.Context, req *ad.ListRequest) (int64, error) {
+					return 2, nil
+				}
 			},
-			expectedAds: []*model.AdWithAuthor{sampleAds[1]},
+			expectedAds:   []*model.AdWithAuthor{sampleAds[1]},
+			expectedTotal: 2,
 		},
 		{
 			name: "filter by price",
 			req: &ad.ListRequest{
 				Page:     1,
 				PageSize: 10,
-				MinPrice: 150,
-				MaxPrice: 250,
+				MinPrice: mustAmount("150"),
+				MaxPrice: mustAmount("250"),
 			},
 			userID: 1,
 			mockSetup: func(m *mockStorage) {
 				m.GetAdsFunc = func(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
-					assert.Equal(t, float64(150), req.MinPrice)
-					assert.Equal(t, float64(250), req.MaxPrice)
+					assert.Equal(t, mustAmount("150"), req.MinPrice)
+					assert.Equal(t, mustAmount("250"), req.MaxPrice)
 					return []*model.AdWithAuthor{sampleAds[1]}, nil
 				}
+				m.CountAdsFunc = func(ctx context.Context, req *ad.ListRequest) (int64, error) {
+					assert.Equal(t, mustAmount("150"), req.MinPrice)
+					assert.Equal(t, mustAmount("250"), req.MaxPrice)
+					return 1, nil
+				}
 			},
-			expectedAds: []*model.AdWithAuthor{sampleAds[1]},
+			expectedAds:   []*model.AdWithAuthor{sampleAds[1]},
+			expectedTotal: 1,
 		},
 		{
 			name: "storage error",
@@ -378,8 +2549,162 @@ func TestService_GetAds(t *testing.T) {
 				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedAds, result)
+				assert.Equal(t, tt.expectedAds, result.Ads)
+				assert.Equal(t, tt.expectedTotal, result.Total)
+				assert.Empty(t, result.NextCursor)
+			}
+		})
+	}
+}
+
+func TestService_GetAds_Cursor(t *testing.T) {
+	now := time.Now().UTC()
+	page := []*model.AdWithAuthor{
+		{ID: 3, Price: 300, CreatedAt: now},
+		{ID: 2, Price: 200, CreatedAt: now.Add(-time.Hour)},
+		{ID: 1, Price: 100, CreatedAt: now.Add(-2 * time.Hour)},
+	}
+
+	mock := &mockStorage{
+		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
+			if req.After == nil {
+				assert.Equal(t, 3, limit)
+				return page, nil
+			}
+			assert.Equal(t, 0, offset)
+			assert.Equal(t, int64(2), req.After.ID)
+			assert.True(t, page[1].CreatedAt.Equal(req.After.CreatedAt))
+			return page[2:], nil
+		},
+		CountAdsFunc: func(ctx context.Context, req *ad.ListRequest) (int64, error) {
+			return int64(len(page)), nil
+		},
+	}
+	s := service.New(mock, "secret")
+
+	first, err := s.GetAds(context.Background(), &ad.ListRequest{Page: 1, PageSize: 2}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, first.Ads, 2)
+	assert.NotEmpty(t, first.NextCursor)
+
+	req, err := s.ParseListRequest(url.Values{"cursor": {first.NextCursor}, "page_size": {"2"}})
+	assert.NoError(t, err)
+
+	second, err := s.GetAds(context.Background(), &req, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, page[2:], second.Ads)
+	assert.Empty(t, second.NextCursor)
+
+	_, err = s.ParseListRequest(url.Values{"cursor": {first.NextCursor}, "sort_by": {"price"}})
+	assert.EqualError(t, err, "cursor does not match sort parameters")
+
+	_, err = service.New(mock, "other").ParseListRequest(url.Values{"cursor": {first.NextCursor}})
+	assert.EqualError(t, err, "invalid cursor value")
+
+	_, err = s.ParseListRequest(url.Values{"cursor": {first.NextCursor}, "currency": {"USD"}})
+	assert.EqualError(t, err, "cursor does not match sort parameters")
+
+	filters := []url.Values{
+		{"q": {"bike"}},
+		{"category": {"2"}},
+		{"min_price": {"10"}},
+		{"max_price": {"10"}},
+		{"min_author_rating": {"4"}},
+	}
+	for _, q := range filters {
+		q.Set("cursor", first.NextCursor)
+		req, err := s.ParseListRequest(q)
+		require.NoError(t, err)
+		_, err = s.GetAds(context.Background(), &req, 0)
+		assert.ErrorIs(t, err, model.ErrCursorMismatch, "filters %v", q)
+	}
+
+	req, err = s.ParseListRequest(url.Values{"cursor": {first.NextCursor}})
+	require.NoError(t, err)
+	req.AuthorID = 7
+	_, err = s.GetAds(context.Background(), &req, 0)
+	assert.ErrorIs(t, err, model.ErrCursorMismatch, "cursor from the feed is rejected on a profile")
+}
+
+func TestService_GetAds_DisplayCurrency(t *testing.T) {
+	page := []*model.AdWithAuthor{
+		{ID: 2, Price: 100, Currency: "USD", DisplayPrice: 9000},
+		{ID: 1, Price: 8000, Currency: "RUB", DisplayPrice: 8000},
+		{ID: 3, Price: 50, Currency: "EUR", DisplayPrice: 4900},
+	}
+
+	mock := &mockStorage{
+		GetAdsFunc: func(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
+			assert.Equal(t, "RUB", req.Currency)
+			if req.After == nil {
+				return page, nil
+			}
+			assert.Equal(t, money.Amount(8000), req.After.Price)
+			return page[2:], nil
+		},
+		CountAdsFunc: func(ctx context.Context, req *ad.ListRequest) (int64, error) {
+			return int64(len(page)), nil
+		},
+		GetRateFunc: func(ctx context.Context, currency string) (*model.ExchangeRate, error) {
+			return nil, sql.ErrNoRows
+		},
+	}
+	s := service.New(mock, "secret")
+
+	req, err := s.ParseListRequest(url.Values{"sort_by": {"price"}, "page_size": {"2"}})
+	require.NoError(t, err)
+
+	first, err := s.GetAds(context.Background(), &req, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "RUB", req.Currency)
+
+	next, err := s.ParseListRequest(url.Values{"sort_by": {"price"}, "page_size": {"2"}, "cursor": {first.NextCursor}})
+	require.NoError(t, err)
+	_, err = s.GetAds(context.Background(), &next, 0)
+	assert.NoError(t, err)
+
+	_, err = s.GetAds(context.Background(), &ad.ListRequest{Page: 1, PageSize: 2, Currency: "JPY"}, 0)
+	assert.ErrorIs(t, err, model.ErrUnsupportedCurrency)
+}
+
+func TestService_UpdateExchangeRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		currency    string
+		rate        string
+		userID      int64
+		expected    string
+		expectedErr string
+	}{
+		{name: "admin updates rate", currency: "usd", rate: "91.2500", userID: 1, expected: "91.25"},
+		{name: "not an admin", currency: "USD", rate: "91", userID: 2, expectedErr: "admin access required"},
+		{name: "base currency", currency: "RUB", rate: "2", userID: 1, expectedErr: "base currency rate is fixed at 1"},
+		{name: "invalid code", currency: "US", rate: "91", userID: 1, expectedErr: money.ErrInvalidCurrency.Error()},
+		{name: "invalid rate", currency: "USD", rate: "0", userID: 1, expectedErr: money.ErrInvalidRate.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var saved *model.ExchangeRate
+			mock := &mockStorage{
+				UpsertRateFunc: func(ctx context.Context, rate *model.ExchangeRate) error {
+					saved = rate
+					return nil
+				},
+			}
+
+			s := service.New(mock, "secret", service.WithAdmins(1))
+			result, err := s.UpdateExchangeRate(context.Background(), tt.currency, tt.rate, tt.userID)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, saved)
+				return
 			}
+			require.NoError(t, err)
+			assert.Equal(t, "USD", result.Currency)
+			assert.Equal(t, tt.expected, result.Rate)
+			assert.Equal(t, result, saved)
 		})
 	}
 }
@@ -406,10 +2731,80 @@ func TestService_ParseListRequest(t *testing.T) {
 			query:    "sort_by=price&sort_order=desc",
 			expected: ad.ListRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "desc"},
 		},
+		{
+			name:          "unknown sort_by",
+			query:         "sort_by=foo",
+			expectedError: "sort_by must be one of created_at, price, relevance",
+		},
+		{
+			name:          "unknown sort_order",
+			query:         "sort_by=price&sort_order=up",
+			expectedError: "sort_order must be asc or desc",
+		},
 		{
 			name:     "price filters",
 			query:    "min_price=100&max_price=500",
-			expected: ad.ListRequest{Page: 1, PageSize: 10, MinPrice: 100, MaxPrice: 500},
+			expected: ad.ListRequest{Page: 1, PageSize: 10, MinPrice: mustAmount("100"), MaxPrice: mustAmount("500")},
+		},
+		{
+			name:     "display currency",
+			query:    "currency=usd",
+			expected: ad.ListRequest{Page: 1, PageSize: 10, Currency: "USD"},
+		},
+		{
+			name:          "invalid currency",
+			query:         "currency=dollars",
+			expectedError: "invalid currency value",
+		},
+		{
+			name:     "decimal price filters",
+			query:    "min_price=0.1&max_price=99.99",
+			expected: ad.ListRequest{Page: 1, PageSize: 10, MinPrice: mustAmount("0.10"), MaxPrice: mustAmount("99.99")},
+		},
+		{
+			name:          "price filter with too many decimals",
+			query:         "min_price=1.999",
+			expectedError: "invalid min_price value: amount must have at most 2 decimal places",
+		},
+		{
+			name:          "price filter over the limit",
+			query:         "max_price=1000000000",
+			expectedError: "invalid max_price value: amount must not exceed 99999999.99",
+		},
+		{
+			name:     "search query",
+			query:    "q=+bicycle+&sort_by=relevance",
+			expected: ad.ListRequest{Page: 1, PageSize: 10, SortBy: "relevance", Query: "bicycle"},
+		},
+		{
+			name:     "author rating filter",
+			query:    "min_author_rating=4.5",
+			expected: ad.ListRequest{Page: 1, PageSize: 10, MinAuthorRating: 4.5},
+		},
+		{
+			name:          "author rating out of range",
+			query:         "min_author_rating=6",
+			expectedError: "min_author_rating must be a number between 1 and 5",
+		},
+		{
+			name:          "author rating not a number",
+			query:         "min_author_rating=NaN",
+			expectedError: "min_author_rating must be a number between 1 and 5",
+		},
+		{
+			name:     "category filter",
+			query:    "category=4",
+			expected: ad.ListRequest{Page: 1, PageSize: 10, Category: 4},
+		},
+		{
+			name:          "invalid category",
+			query:         "category=phones",
+			expectedError: "invalid category value",
+		},
+		{
+			name:          "relevance without query",
+			query:         "sort_by=relevance",
+			expectedError: "sort_by=relevance requires q",
 		},
 		{
 			name:          "invalid page",
@@ -453,3 +2848,402 @@ func TestService_ParseListRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestService_GetCategoryTree(t *testing.T) {
+	mock := &mockStorage{
+		GetCategoriesFunc: func(ctx context.Context) ([]*model.Category, error) {
+			return []*model.Category{
+				{ID: 1, Name: "Electronics", Slug: "electronics"},
+				{ID: 2, Name: "Phones", Slug: "phones", ParentID: 1},
+				{ID: 3, Name: "Smartphones", Slug: "smartphones", ParentID: 2},
+				{ID: 4, Name: "Books", Slug: "books"},
+			}, nil
+		},
+	}
+
+	s := service.New(mock, "secret")
+	tree, err := s.GetCategoryTree(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "electronics", tree[0].Slug)
+	assert.Len(t, tree[0].Children, 1)
+	assert.Equal(t, "phones", tree[0].Children[0].Slug)
+	assert.Equal(t, "smartphones", tree[0].Children[0].Children[0].Slug)
+	assert.Empty(t, tree[1].Children)
+}
+
+func TestService_UploadImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		storageErr  error
+		expectedErr string
+	}{
+		{
+			name: "successful upload",
+			data: encodePNG(t, 400, 100),
+		},
+		{
+			name:        "too large",
+			data:        bytes.Repeat([]byte{0}, 4097),
+			expectedErr: "image is too large",
+		},
+		{
+			name:        "not an image",
+			data:        []byte("%PDF-1.4 definitely not a picture"),
+			expectedErr: "unsupported image format",
+		},
+		{
+			name:        "truncated image",
+			data:        encodePNG(t, 64, 32)[:60],
+			expectedErr: "unsupported image format",
+		},
+		{
+			name:        "dimensions over limit",
+			data:        encodePNG(t, 600, 10),
+			expectedErr: "image dimensions exceed the allowed limit",
+		},
+		{
+			name:        "storage error removes blob",
+			data:        encodePNG(t, 10, 10),
+			storageErr:  errors.New("storage error"),
+			expectedErr: "storage error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryBlobStore{objects: map[string][]byte{}}
+			mock := &mockStorage{
+				CreateImageFunc: func(ctx context.Context, img *model.Image) error {
+					if tt.storageErr != nil {
+						return tt.storageErr
+					}
+					img.ID = 1
+					return nil
+				},
+			}
+
+			s := service.New(mock, "secret", service.WithImages(store, config.Images{
+				MaxBytes:  4096,
+				MaxWidth:  500,
+				MaxHeight: 500,
+			}))
+			img, err := s.UploadImage(context.Background(), bytes.NewReader(tt.data), 7)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, img)
+				assert.Empty(t, store.objects)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(7), img.OwnerID)
+				assert.Equal(t, "image/png", img.ContentType)
+				assert.Equal(t, 400, img.Width)
+				assert.Equal(t, 100, img.Height)
+				assert.Equal(t, "http://cdn.test/"+img.StorageKey, img.URL)
+				assert.Contains(t, store.objects, img.StorageKey)
+				assert.Len(t, store.objects, 3)
+
+				thumbKey := strings.TrimPrefix(img.Variants.Thumbnail, "http://cdn.test/")
+				thumb, format, err := image.Decode(bytes.NewReader(store.objects[thumbKey]))
+				require.NoError(t, err)
+				assert.Equal(t, "jpeg", format)
+				assert.Equal(t, 200, thumb.Bounds().Dx())
+				assert.Equal(t, 50, thumb.Bounds().Dy())
+
+				mediumKey := strings.TrimPrefix(img.Variants.Medium, "http://cdn.test/")
+				medium, _, err := image.Decode(bytes.NewReader(store.objects[mediumKey]))
+				require.NoError(t, err)
+				assert.Equal(t, 400, medium.Bounds().Dx())
+			}
+		})
+	}
+}
+
+func TestService_CreateAd_TitlePolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		title       string
+		cfg         *config.Titles
+		expected    string
+		expectedErr string
+	}{
+		{name: "cyrillic", title: "Удочка для рыбалки", expected: "Удочка для рыбалки"},
+		{name: "punctuation", title: "iPhone 13, 128GB (б/у)", expected: "iPhone 13, 128GB (б/у)"},
+		{name: "accented letters", title: "Crème brûlée", expected: "Crème brûlée"},
+		{name: "normalized to NFC", title: "Cafe\u0301", expected: "Caf\u00e9"},
+		{name: "invisible characters stripped", title: "Ve\u200blo\u0007 \t\u00a0 bike\ufeff", expected: "Velo bike"},
+		{name: "empty after normalization", title: "\u200b \u200d", expectedErr: "title: must not be empty"},
+		{name: "symbol rejected", title: "Best deal $$$", expectedErr: "title: character '$' is not allowed"},
+		{name: "emoji rejected", title: "Bike 🚲", expectedErr: "title: character '🚲' is not allowed"},
+		{
+			name:     "symbols allowed by config",
+			title:    "Bike 🚲 $100",
+			cfg:      &config.Titles{AllowSymbols: true},
+			expected: "Bike 🚲 $100",
+		},
+		{
+			name:        "custom punctuation",
+			title:       "Bike, red",
+			cfg:         &config.Titles{Punctuation: "-"},
+			expectedErr: "title: character ',' is not allowed",
+		},
+		{
+			name:        "too long",
+			title:       strings.Repeat("я", 11),
+			cfg:         &config.Titles{MaxLength: 10},
+			expectedErr: "title: must be at most 10 characters, got 11",
+		},
+		{
+			name:        "limit capped at column size",
+			title:       strings.Repeat("я", 101),
+			cfg:         &config.Titles{MaxLength: 500},
+			expectedErr: "title: must be at most 100 characters, got 101",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockStorage{
+				CreateAdFunc: func(ctx context.Context, ad *model.Ad) error {
+					return nil
+				},
+			}
+
+			var opts []service.Option
+			if tt.cfg != nil {
+				opts = append(opts, service.WithTitles(*tt.cfg))
+			}
+			s := service.New(mock, "secret", opts...)
+
+			req := ad.CreateRequest{
+				Title:       tt.title,
+				Description: "Line one\r\nline\u200b two ",
+				ImageURL:    "http://example.com/bike.jpg",
+				Price:       100,
+			}
+			created, err := s.CreateAd(context.Background(), req, 1)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				var fieldErr *model.FieldError
+				require.ErrorAs(t, err, &fieldErr)
+				assert.Equal(t, "title", fieldErr.Field)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, created.Title)
+			assert.Equal(t, "Line one\nline two", created.Description)
+		})
+	}
+}
+
+func TestService_CreateAd_RequiresUploadedImage(t *testing.T) {
+	mock := &mockStorage{
+		GetImageByURLFunc: func(ctx context.Context, url string) (*model.Image, error) {
+			if url == "http://cdn.test/mine.png" {
+				return &model.Image{
+					URL:      url,
+					OwnerID:  1,
+					Variants: model.ImageVariants{Thumbnail: "http://cdn.test/mine_thumb.jpg"},
+				}, nil
+			}
+			return nil, sql.ErrNoRows
+		},
+		CreateAdFunc: func(ctx context.Context, ad *model.Ad) error {
+			ad.ID = 1
+			return nil
+		},
+	}
+	store := &memoryBlobStore{objects: map[string][]byte{}}
+	s := service.New(mock, "secret", service.WithImages(store, config.Images{}))
+
+	req := ad.CreateRequest{Title: "Bike", Description: "Red", Price: 10}
+
+	req.ImageURL = "http://evil.example/payload.pdf"
+	_, err := s.CreateAd(context.Background(), req, 1)
+	assert.ErrorIs(t, err, model.ErrImageNotUploaded)
+
+	req.ImageURL = "http://cdn.test/mine.png"
+	_, err = s.CreateAd(context.Background(), req, 2)
+	assert.ErrorIs(t, err, model.ErrImageNotUploaded)
+
+	created, err := s.CreateAd(context.Background(), req, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, req.ImageURL, created.ImageURL)
+	assert.Equal(t, "http://cdn.test/mine_thumb.jpg", created.ImageVariants.Thumbnail)
+}
+
+func TestService_CreateAd_Gallery(t *testing.T) {
+	tests := []struct {
+		name        string
+		images      []string
+		expectedErr string
+	}{
+		{
+			name:   "ordered gallery",
+			images: []string{"http://example.com/1.jpg", "http://example.com/2.jpg", "http://example.com/3.jpg"},
+		},
+		{
+			name:        "duplicate image",
+			images:      []string{"http://example.com/1.jpg", "http://example.com/1.jpg"},
+			expectedErr: "duplicate image in gallery",
+		},
+		{
+			name: "too many images",
+			images: []string{
+				"http://example.com/1.jpg", "http://example.com/2.jpg", "http://example.com/3.jpg",
+				"http://example.com/4.jpg", "http://example.com/5.jpg", "http://example.com/6.jpg",
+				"http://example.com/7.jpg", "http://example.com/8.jpg", "http://example.com/9.jpg",
+				"http://example.com/10.jpg", "http://example.com/11.jpg",
+			},
+			expectedErr: "too many images",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockStorage{
+				CreateAdFunc: func(ctx context.Context, ad *model.Ad) error {
+					ad.ID = 1
+					return nil
+				},
+			}
+
+			s := service.New(mock, "secret")
+			result, err := s.CreateAd(context.Background(), ad.CreateRequest{
+				Title:       "Car",
+				Description: "Red car",
+				Images:      tt.images,
+				Price:       100,
+			}, 1)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.images[0], result.ImageURL)
+				require.Len(t, result.Images, len(tt.images))
+				for i, img := range result.Images {
+					assert.Equal(t, tt.images[i], img.URL)
+					assert.Equal(t, i, img.Position)
+				}
+			}
+		})
+	}
+}
+
+func TestService_ReplaceAdImages(t *testing.T) {
+	existing := &model.AdWithAuthor{ID: 5, AuthorID: 1}
+	gallery := map[int64][]model.AdImage{
+		5: {
+			{ID: 10, AdID: 5, URL: "http://example.com/a.jpg", Position: 0},
+			{ID: 11, AdID: 5, URL: "http://example.com/b.jpg", Position: 1},
+		},
+	}
+
+	newMock := func(stored *[]model.AdImage) *mockStorage {
+		return &mockStorage{
+			GetAdByIDFunc: func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+				copied := *existing
+				return &copied, nil
+			},
+			GetAdImagesFunc: func(ctx context.Context, adIDs []int64) (map[int64][]model.AdImage, error) {
+				return gallery, nil
+			},
+			ReplaceImagesFunc: func(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error {
+				*stored = images
+				return nil
+			},
+		}
+	}
+
+	t.Run("reorder", func(t *testing.T) {
+		var stored []model.AdImage
+		s := service.New(newMock(&stored), "secret")
+
+		images, err := s.ReplaceAdImages(context.Background(), 5, []string{"http://example.com/b.jpg", "http://example.com/a.jpg"}, 1)
+		assert.NoError(t, err)
+		require.Len(t, stored, 2)
+		assert.Equal(t, "http://example.com/b.jpg", stored[0].URL)
+		assert.Equal(t, 1, stored[1].Position)
+		assert.Equal(t, stored, images)
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		var stored []model.AdImage
+		s := service.New(newMock(&stored), "secret")
+
+		_, err := s.ReplaceAdImages(context.Background(), 5, []string{"http://example.com/b.jpg"}, 2)
+		assert.ErrorIs(t, err, model.ErrNotAdAuthor)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("delete image", func(t *testing.T) {
+		var stored []model.AdImage
+		s := service.New(newMock(&stored), "secret")
+
+		_, err := s.DeleteAdImage(context.Background(), 5, 10, 1)
+		assert.NoError(t, err)
+		require.Len(t, stored, 1)
+		assert.Equal(t, "http://example.com/b.jpg", stored[0].URL)
+		assert.Equal(t, 0, stored[0].Position)
+	})
+
+	t.Run("resubmits a rejected ad with the images", func(t *testing.T) {
+		var stored []model.AdImage
+		var gotStatus string
+		var gotChange *model.AdStatusChange
+		mock := newMock(&stored)
+		mock.GetAdByIDFunc = func(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+			return &model.AdWithAuthor{ID: 5, AuthorID: 1, Status: model.AdStatusRejected}, nil
+		}
+		mock.ReplaceImagesFunc = func(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error {
+			stored, gotStatus, gotChange = images, status, change
+			return nil
+		}
+		s := service.New(mock, "secret")
+
+		_, err := s.ReplaceAdImages(context.Background(), 5, []string{"http://example.com/b.jpg"}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, model.AdStatusRejected, gotStatus, "the update is guarded by the status the author saw")
+		require.NotNil(t, gotChange)
+		assert.Equal(t, model.AdStatusRejected, gotChange.From)
+		assert.Equal(t, model.AdStatusPending, gotChange.To)
+	})
+
+	t.Run("status changed concurrently", func(t *testing.T) {
+		var stored []model.AdImage
+		mock := newMock(&stored)
+		mock.ReplaceImagesFunc = func(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error {
+			return sql.ErrNoRows
+		}
+		s := service.New(mock, "secret")
+
+		_, err := s.ReplaceAdImages(context.Background(), 5, []string{"http://example.com/b.jpg"}, 1)
+		assert.ErrorIs(t, err, model.ErrAdStatusChanged)
+	})
+
+	t.Run("delete unknown image", func(t *testing.T) {
+		var stored []model.AdImage
+		s := service.New(newMock(&stored), "secret")
+
+		_, err := s.DeleteAdImage(context.Background(), 5, 99, 1)
+		assert.ErrorIs(t, err, model.ErrAdImageNotFound)
+	})
+
+	t.Run("delete last image", func(t *testing.T) {
+		var stored []model.AdImage
+		mock := newMock(&stored)
+		mock.GetAdImagesFunc = func(ctx context.Context, adIDs []int64) (map[int64][]model.AdImage, error) {
+			return map[int64][]model.AdImage{5: gallery[5][:1]}, nil
+		}
+		s := service.New(mock, "secret")
+
+		_, err := s.DeleteAdImage(context.Background(), 5, 10, 1)
+		assert.ErrorIs(t, err, model.ErrNoImages)
+	})
+}
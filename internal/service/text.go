@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxTitleLength          = 100
+	defaultTitlePunctuation = `.,:;!?'"«»()[]-–—/&+#%№`
+)
+
+func WithTitles(cfg config.Titles) Option {
+	return func(s *Service) {
+		if cfg.MaxLength <= 0 || cfg.MaxLength > maxTitleLength {
+			cfg.MaxLength = maxTitleLength
+		}
+		if cfg.Punctuation == "" {
+			cfg.Punctuation = defaultTitlePunctuation
+		}
+		s.titles = cfg
+	}
+}
+
+func (s *Service) normalizeTitle(title string) (string, error) {
+	title = norm.NFC.String(strings.Join(strings.Fields(stripInvisible(title, false)), " "))
+
+	if title == "" {
+		return "", &model.FieldError{Field: "title", Reason: "must not be empty"}
+	}
+	if n := utf8.RuneCountInString(title); n > s.titles.MaxLength {
+		return "", &model.FieldError{
+			Field:  "title",
+			Reason: fmt.Sprintf("must be at most %d characters, got %d", s.titles.MaxLength, n),
+		}
+	}
+
+	for _, r := range title {
+		if !s.titleRuneAllowed(r) {
+			return "", &model.FieldError{Field: "title", Reason: fmt.Sprintf("character %q is not allowed", r)}
+		}
+	}
+
+	return title, nil
+}
+
+func (s *Service) titleRuneAllowed(r rune) bool {
+	switch {
+	case r == ' ', unicode.IsLetter(r), unicode.IsMark(r), unicode.IsDigit(r):
+		return true
+	case s.titles.AllowSymbols && unicode.IsSymbol(r):
+		return true
+	default:
+		return strings.ContainsRune(s.titles.Punctuation, r)
+	}
+}
+
+func normalizeAdDescription(description string) (string, error) {
+	description = normalizeDescription(description)
+	if description == "" {
+		return "", &model.FieldError{Field: "description", Reason: "must not be empty"}
+	}
+	return description, nil
+}
+
+func normalizeDescription(description string) string {
+	description = strings.ReplaceAll(description, "\r\n", "\n")
+	return strings.TrimSpace(norm.NFC.String(stripInvisible(description, true)))
+}
+
+func stripInvisible(text string, keepNewlines bool) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range text {
+		switch {
+		case r == '\n' && keepNewlines:
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsControl(r), unicode.Is(unicode.Cf, r), r == utf8.RuneError:
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
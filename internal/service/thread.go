@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const maxMessageLen = 2000
+
+func (s *Service) StartThread(ctx context.Context, adID int64, message string, userID int64) (*model.Thread, bool, error) {
+	ad, err := s.GetVisibleAd(ctx, adID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if ad.AuthorID == userID {
+		return nil, false, model.ErrMessageOwnAd
+	}
+
+	body := normalizeDescription(message)
+	if err := validateMessage("message", body); err != nil {
+		return nil, false, err
+	}
+
+	thread := &model.Thread{
+		AdID:      ad.ID,
+		AdTitle:   ad.Title,
+		BuyerID:   userID,
+		SellerID:  ad.AuthorID,
+		CreatedAt: time.Now(),
+	}
+	thread.LastMessageAt = thread.CreatedAt
+
+	created := true
+	if err := s.storage.CreateThread(ctx, thread); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, err
+		}
+		created = false
+	}
+
+	thread, err = s.storage.FindThread(ctx, ad.ID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	msg, err := s.postMessage(ctx, thread, body, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	thread.LastMessage = msg
+	thread.LastMessageAt = msg.CreatedAt
+
+	return thread, created, nil
+}
+
+func (s *Service) PostMessage(ctx context.Context, threadID int64, message string, userID int64) (*model.Message, error) {
+	thread, err := s.participantThread(ctx, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := normalizeDescription(message)
+	if err := validateMessage("body", body); err != nil {
+		return nil, err
+	}
+
+	return s.postMessage(ctx, thread, body, userID)
+}
+
+func (s *Service) GetThreads(ctx context.Context, page, pageSize int, userID int64) (*model.ThreadList, error) {
+	threads, err := s.storage.GetUserThreads(ctx, userID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	total, unread, err := s.storage.CountUserThreads(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ThreadList{Items: threads, Total: total, Unread: unread}, nil
+}
+
+func (s *Service) GetThreadMessages(ctx context.Context, threadID, afterID int64, limit int, userID int64) ([]model.Message, error) {
+	if _, err := s.participantThread(ctx, threadID, userID); err != nil {
+		return nil, err
+	}
+	return s.storage.GetMessages(ctx, threadID, afterID, limit)
+}
+
+func (s *Service) MarkThreadRead(ctx context.Context, threadID, userID int64) error {
+	if _, err := s.participantThread(ctx, threadID, userID); err != nil {
+		return err
+	}
+	if err := s.storage.MarkThreadRead(ctx, threadID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrThreadNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) postMessage(ctx context.Context, thread *model.Thread, body string, userID int64) (*model.Message, error) {
+	msg := &model.Message{
+		ThreadID:  thread.ID,
+		SenderID:  userID,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+	if err := s.storage.CreateMessage(ctx, msg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrThreadNotFound
+		}
+		return nil, err
+	}
+
+	s.notifyMessage(thread, msg)
+	return msg, nil
+}
+
+func (s *Service) participantThread(ctx context.Context, threadID, userID int64) (*model.Thread, error) {
+	thread, err := s.storage.GetThread(ctx, threadID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrThreadNotFound
+		}
+		return nil, err
+	}
+	if thread.BuyerID != userID && thread.SellerID != userID {
+		return nil, model.ErrNotThreadParticipant
+	}
+	return thread, nil
+}
+
+func validateMessage(field, body string) error {
+	if body == "" {
+		return &model.FieldError{Field: field, Reason: "must not be empty"}
+	}
+	if n := utf8.RuneCountInString(body); n > maxMessageLen {
+		return &model.FieldError{Field: field, Reason: fmt.Sprintf("must be at most %d characters, got %d", maxMessageLen, n)}
+	}
+	return nil
+}
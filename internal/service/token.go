@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/config"
+	"github.com/AugustSerenity/marketplace/internal/handler/model/auth"
+	"github.com/AugustSerenity/marketplace/internal/jwtkeys"
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/AugustSerenity/marketplace/internal/revocation"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var errRevocationDisabled = errors.New("token revocation is not configured")
+
+const (
+	defaultAccessTTL            = 15 * time.Minute
+	defaultRefreshTTL           = 30 * 24 * time.Hour
+	defaultPasswordResetTTL     = time.Hour
+	defaultEmailVerificationTTL = 48 * time.Hour
+)
+
+func WithAuth(cfg config.Auth) Option {
+	return func(s *Service) {
+		if cfg.AccessTTL <= 0 {
+			cfg.AccessTTL = defaultAccessTTL
+		}
+		if cfg.RefreshTTL <= 0 {
+			cfg.RefreshTTL = defaultRefreshTTL
+		}
+		if cfg.PasswordResetTTL <= 0 {
+			cfg.PasswordResetTTL = defaultPasswordResetTTL
+		}
+		if cfg.EmailVerificationTTL <= 0 {
+			cfg.EmailVerificationTTL = defaultEmailVerificationTTL
+		}
+		s.auth = cfg
+	}
+}
+
+func WithRevocation(store revocation.Store) Option {
+	return func(s *Service) {
+		s.revoked = store
+	}
+}
+
+func WithSigner(signer jwtkeys.Signer) Option {
+	return func(s *Service) {
+		s.signer = signer
+	}
+}
+
+func (s *Service) PublicKeys() jwtkeys.JWKS {
+	if set, ok := s.signer.(interface{ JWKS() jwtkeys.JWKS }); ok {
+		return set.JWKS()
+	}
+	return jwtkeys.JWKS{Keys: []jwtkeys.JWK{}}
+}
+
+func (s *Service) RefreshTokens(ctx context.Context, refreshToken string) (*auth.LoginResponse, error) {
+	current, err := s.storage.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if current.UsedAt != nil || current.RevokedAt != nil {
+		if err := s.storage.RevokeRefreshTokenFamily(ctx, current.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, model.ErrRefreshTokenReused
+	}
+	if !now.Before(current.ExpiresAt) {
+		return nil, model.ErrInvalidRefreshToken
+	}
+
+	user, err := s.storage.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	plain, next, err := s.newRefreshToken(current.UserID, current.FamilyID, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.RotateRefreshToken(ctx, current.ID, next, now); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := s.storage.RevokeRefreshTokenFamily(ctx, current.FamilyID, now); err != nil {
+				return nil, err
+			}
+			return nil, model.ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	return s.tokenResponse(user, plain, now)
+}
+
+func (s *Service) Logout(ctx context.Context, refreshToken string) error {
+	current, err := s.storage.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	return s.storage.RevokeRefreshTokenFamily(ctx, current.FamilyID, time.Now())
+}
+
+func (s *Service) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if s.revoked == nil {
+		return errRevocationDisabled
+	}
+	return s.revoked.Revoke(ctx, tokenID, expiresAt)
+}
+
+func (s *Service) RevokeAllTokens(ctx context.Context, userID int64) error {
+	if s.revoked == nil {
+		return errRevocationDisabled
+	}
+
+	return s.revokeSessions(ctx, userID, time.Now())
+}
+
+func (s *Service) RevokeUserTokens(ctx context.Context, targetID, userID int64) error {
+	if err := s.requireAdmin(ctx, userID); err != nil {
+		return err
+	}
+	return s.RevokeAllTokens(ctx, targetID)
+}
+
+func (s *Service) issueTokens(ctx context.Context, user *model.User) (*auth.LoginResponse, error) {
+	family, err := randomToken(16)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	now := time.Now()
+	plain, token, err := s.newRefreshToken(user.ID, family, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.storage.CreateRefreshToken(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(user, plain, now)
+}
+
+func (s *Service) newRefreshToken(userID int64, family string, now time.Time) (string, *model.RefreshToken, error) {
+	plain, err := randomToken(32)
+	if err != nil {
+		return "", nil, errors.New("failed to generate token")
+	}
+
+	return plain, &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  family,
+		TokenHash: hashToken(plain),
+		ExpiresAt: now.Add(s.auth.RefreshTTL),
+		CreatedAt: now,
+	}, nil
+}
+
+func (s *Service) tokenResponse(user *model.User, refreshToken string, now time.Time) (*auth.LoginResponse, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	tokenString, err := s.signer.Sign(jwt.MapClaims{
+		"sub":  user.ID,
+		"role": s.roleOf(user),
+		"jti":  jti,
+		"iat":  float64(now.UnixMilli()) / 1000,
+		"exp":  now.Add(s.auth.AccessTTL).Unix(),
+	})
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &auth.LoginResponse{
+		Token:        tokenString,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.auth.AccessTTL / time.Second),
+	}, nil
+}
+
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
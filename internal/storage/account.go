@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Storage) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1 AND deleted_at IS NULL`, userID, passwordHash)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *Storage) CreatePasswordReset(ctx context.Context, reset *model.PasswordReset) error {
+	query := `
+		INSERT INTO password_resets (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		reset.UserID,
+		reset.TokenHash,
+		reset.ExpiresAt,
+		reset.CreatedAt,
+	).Scan(&reset.ID)
+}
+
+func (s *Storage) GetPasswordReset(ctx context.Context, tokenHash string) (*model.PasswordReset, error) {
+	var r model.PasswordReset
+	query := `
+		SELECT id, user_id, token_hash, expires_at, used_at, created_at
+		FROM password_resets
+		WHERE token_hash = $1
+	`
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&r.ID,
+		&r.UserID,
+		&r.TokenHash,
+		&r.ExpiresAt,
+		&r.UsedAt,
+		&r.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *Storage) ResetPassword(ctx context.Context, reset *model.PasswordReset, passwordHash string, usedAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE password_resets SET used_at = $2 WHERE id = $1 AND used_at IS NULL`, reset.ID, usedAt)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	query := `UPDATE password_resets SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, reset.UserID, usedAt); err != nil {
+		return err
+	}
+
+	res, err = tx.ExecContext(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1 AND deleted_at IS NULL`, reset.UserID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) AnonymizeUser(ctx context.Context, userID int64, login string, deletedAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET login = $2, password_hash = '', display_name = '', about = '', avatar_url = '', location = '',
+			email = NULL, email_verified_at = NULL, deleted_at = $3
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	res, err := tx.ExecContext(ctx, query, userID, login, deletedAt)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE ads
+		SET status = 'archived', status_changed_by = $1, status_changed_at = $2, updated_at = $2
+		WHERE author_id = $1 AND status <> 'archived'
+	`
+	if _, err := tx.ExecContext(ctx, query, userID, deletedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM favorites WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE messages SET body = '' WHERE sender_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE reviews SET text = '' WHERE reviewer_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM password_resets WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM email_verifications WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
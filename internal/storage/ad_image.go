@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/lib/pq"
+)
+
+func (s *Storage) GetAdImages(ctx context.Context, adIDs []int64) (map[int64][]model.AdImage, error) {
+	query := `
+		SELECT
+			ai.id,
+			ai.ad_id,
+			ai.url,
+			ai.position,
+			COALESCE(i.thumbnail_url, ''),
+			COALESCE(i.medium_url, '')
+		FROM ad_images ai
+		LEFT JOIN images i ON i.url = ai.url
+		WHERE ai.ad_id = ANY($1)
+		ORDER BY ai.ad_id, ai.position
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(adIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := make(map[int64][]model.AdImage, len(adIDs))
+	for rows.Next() {
+		var img model.AdImage
+		if err := rows.Scan(
+			&img.ID,
+			&img.AdID,
+			&img.URL,
+			&img.Position,
+			&img.Variants.Thumbnail,
+			&img.Variants.Medium,
+		); err != nil {
+			return nil, err
+		}
+		images[img.AdID] = append(images[img.AdID], img)
+	}
+
+	return images, rows.Err()
+}
+
+func (s *Storage) ReplaceAdImages(ctx context.Context, adID, authorID int64, status string, images []model.AdImage, change *model.AdStatusChange, updatedAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE ads SET image_url = $1, updated_at = $2 WHERE id = $3 AND author_id = $4 AND status = $5`,
+		images[0].URL,
+		updatedAt,
+		adID,
+		authorID,
+		status,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	if err := replaceAdImages(ctx, tx, adID, images); err != nil {
+		return err
+	}
+
+	if change != nil {
+		if err := updateAdStatus(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func replaceAdImages(ctx context.Context, tx *sql.Tx, adID int64, images []model.AdImage) error {
+	urls := make([]string, 0, len(images))
+	for _, img := range images {
+		urls = append(urls, img.URL)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ad_images WHERE ad_id = $1 AND url <> ALL($2)`, adID, pq.Array(urls)); err != nil {
+		return err
+	}
+
+	// (ad_id, position) is unique, so kept rows are moved past the new range before being renumbered.
+	query := `
+		UPDATE ad_images
+		SET position = position + $2 + (SELECT MAX(position) + 1 FROM ad_images WHERE ad_id = $1)
+		WHERE ad_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, query, adID, len(images)); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO ad_images (ad_id, url, position)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (ad_id, url) DO UPDATE SET position = EXCLUDED.position
+		RETURNING id
+	`
+	for i := range images {
+		images[i].AdID = adID
+		if err := tx.QueryRowContext(ctx, query, adID, images[i].URL, images[i].Position).Scan(&images[i].ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
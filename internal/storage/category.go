@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Storage) GetCategories(ctx context.Context) ([]*model.Category, error) {
+	query := `SELECT id, name, slug, COALESCE(parent_id, 0) FROM categories ORDER BY name`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*model.Category
+	for rows.Next() {
+		var c model.Category
+		if err := rows.Scan(&c.ID, &c.Name, &c.Slug, &c.ParentID); err != nil {
+			return nil, err
+		}
+		categories = append(categories, &c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (s *Storage) GetCategoryByID(ctx context.Context, id int64) (*model.Category, error) {
+	var c model.Category
+	query := `SELECT id, name, slug, COALESCE(parent_id, 0) FROM categories WHERE id = $1`
+	err := s.db.QueryRowContext(ctx, query, id).Scan(&c.ID, &c.Name, &c.Slug, &c.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Storage) GetExchangeRates(ctx context.Context) ([]model.ExchangeRate, error) {
+	query := `SELECT currency, trim_scale(rate)::text, updated_at FROM exchange_rates ORDER BY currency`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []model.ExchangeRate
+	for rows.Next() {
+		var r model.ExchangeRate
+		if err := rows.Scan(&r.Currency, &r.Rate, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, r)
+	}
+
+	return rates, rows.Err()
+}
+
+func (s *Storage) GetExchangeRate(ctx context.Context, currency string) (*model.ExchangeRate, error) {
+	var r model.ExchangeRate
+	query := `SELECT currency, trim_scale(rate)::text, updated_at FROM exchange_rates WHERE currency = $1`
+	err := s.db.QueryRowContext(ctx, query, currency).Scan(&r.Currency, &r.Rate, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *Storage) UpsertExchangeRate(ctx context.Context, rate *model.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (currency, rate, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (currency) DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at
+	`
+	_, err := s.db.ExecContext(ctx, query, rate.Currency, rate.Rate, rate.UpdatedAt)
+	return err
+}
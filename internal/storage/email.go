@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Storage) EmailInUse(ctx context.Context, email string, exceptUserID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE email = $1 AND id <> $2)`
+	err := s.db.QueryRowContext(ctx, query, email, exceptUserID).Scan(&exists)
+	return exists, err
+}
+
+func (s *Storage) UpdateEmail(ctx context.Context, userID int64, email string) error {
+	query := `
+		UPDATE users
+		SET email = $2, email_verified_at = NULL
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	res, err := s.db.ExecContext(ctx, query, userID, email)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *Storage) CreateEmailVerification(ctx context.Context, v *model.EmailVerification) error {
+	query := `
+		INSERT INTO email_verifications (user_id, email, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		v.UserID,
+		v.Email,
+		v.TokenHash,
+		v.ExpiresAt,
+		v.CreatedAt,
+	).Scan(&v.ID)
+}
+
+func (s *Storage) GetEmailVerification(ctx context.Context, tokenHash string) (*model.EmailVerification, error) {
+	var v model.EmailVerification
+	query := `
+		SELECT id, user_id, email, token_hash, expires_at, used_at, created_at
+		FROM email_verifications
+		WHERE token_hash = $1
+	`
+	err := s.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&v.ID,
+		&v.UserID,
+		&v.Email,
+		&v.TokenHash,
+		&v.ExpiresAt,
+		&v.UsedAt,
+		&v.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (s *Storage) VerifyEmail(ctx context.Context, v *model.EmailVerification, verifiedAt time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE email_verifications SET used_at = $2 WHERE id = $1 AND used_at IS NULL`, v.ID, verifiedAt)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	query := `UPDATE email_verifications SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, v.UserID, verifiedAt); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE users
+		SET email_verified_at = $3
+		WHERE id = $1 AND email = $2 AND deleted_at IS NULL
+	`
+	res, err = tx.ExecContext(ctx, query, v.UserID, v.Email, verifiedAt)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Storage) AddFavorite(ctx context.Context, userID, adID int64, createdAt time.Time) error {
+	query := `
+		INSERT INTO favorites (user_id, ad_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, ad_id) DO NOTHING
+	`
+	_, err := s.db.ExecContext(ctx, query, userID, adID, createdAt)
+	return err
+}
+
+func (s *Storage) RemoveFavorite(ctx context.Context, userID, adID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM favorites WHERE user_id = $1 AND ad_id = $2`, userID, adID)
+	return err
+}
+
+func (s *Storage) GetFavorites(ctx context.Context, userID int64, currency string, offset, limit int) ([]*model.AdWithAuthor, error) {
+	query := `
+		SELECT
+			a.id,
+			a.title,
+			a.description,
+			a.image_url,
+			COALESCE(i.thumbnail_url, ''),
+			COALESCE(i.medium_url, ''),
+			a.price,
+			a.currency,
+			ROUND(a.price * ar.rate / dr.rate, 2),
+			a.author_id,
+			COALESCE(a.category_id, 0),
+			a.created_at,
+			u.login as author_login,
+			` + authorRating + `
+		FROM favorites f
+		JOIN ads a ON a.id = f.ad_id
+		JOIN users u ON a.author_id = u.id
+		LEFT JOIN images i ON i.url = a.image_url
+		JOIN exchange_rates ar ON ar.currency = a.currency
+		JOIN exchange_rates dr ON dr.currency = $2
+		WHERE f.user_id = $1 AND a.status = 'published' AND a.hidden_at IS NULL
+		ORDER BY f.created_at DESC, a.id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, currency, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ads []*model.AdWithAuthor
+	for rows.Next() {
+		ad := model.AdWithAuthor{IsFavorite: true}
+		if err := rows.Scan(
+			&ad.ID,
+			&ad.Title,
+			&ad.Description,
+			&ad.ImageURL,
+			&ad.ImageVariants.Thumbnail,
+			&ad.ImageVariants.Medium,
+			&ad.Price,
+			&ad.Currency,
+			&ad.DisplayPrice,
+			&ad.AuthorID,
+			&ad.CategoryID,
+			&ad.CreatedAt,
+			&ad.AuthorLogin,
+			&ad.AuthorRating,
+		); err != nil {
+			return nil, err
+		}
+		ads = append(ads, &ad)
+	}
+
+	return ads, rows.Err()
+}
+
+func (s *Storage) CountFavorites(ctx context.Context, userID int64) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM favorites f
+		JOIN ads a ON a.id = f.ad_id
+		WHERE f.user_id = $1 AND a.status = 'published' AND a.hidden_at IS NULL
+	`
+
+	var total int64
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *Storage) GetFavoriteUserIDs(ctx context.Context, adID int64) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT user_id FROM favorites WHERE ad_id = $1`, adID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
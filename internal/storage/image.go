@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+func (s *Storage) CreateImage(ctx context.Context, img *model.Image) error {
+	query := `
+		INSERT INTO images (owner_id, storage_key, url, content_type, size_bytes, width, height, created_at, thumbnail_url, medium_url)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		img.OwnerID,
+		img.StorageKey,
+		img.URL,
+		img.ContentType,
+		img.SizeBytes,
+		img.Width,
+		img.Height,
+		img.CreatedAt,
+		img.Variants.Thumbnail,
+		img.Variants.Medium,
+	).Scan(&img.ID)
+}
+
+func (s *Storage) GetImageByURL(ctx context.Context, url string) (*model.Image, error) {
+	var img model.Image
+	query := `
+		SELECT id, owner_id, storage_key, url, content_type, size_bytes, width, height, created_at, thumbnail_url, medium_url
+		FROM images
+		WHERE url = $1
+	`
+
+	err := s.db.QueryRowContext(ctx, query, url).Scan(
+		&img.ID,
+		&img.OwnerID,
+		&img.StorageKey,
+		&img.URL,
+		&img.ContentType,
+		&img.SizeBytes,
+		&img.Width,
+		&img.Height,
+		&img.CreatedAt,
+		&img.Variants.Thumbnail,
+		&img.Variants.Medium,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &img, nil
+}
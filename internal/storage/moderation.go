@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/lib/pq"
+)
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (s *Storage) UpdateAdStatus(ctx context.Context, change *model.AdStatusChange) error {
+	return updateAdStatus(ctx, s.db, change)
+}
+
+func updateAdStatus(ctx context.Context, db execer, change *model.AdStatusChange) error {
+	query := `
+		UPDATE ads
+		SET status = $1, rejection_reason = NULLIF($2, ''), status_changed_by = $3, status_changed_at = $4
+		WHERE id = $5 AND status = $6
+	`
+
+	res, err := db.ExecContext(
+		ctx,
+		query,
+		change.To,
+		change.Reason,
+		change.ChangedBy,
+		change.ChangedAt,
+		change.AdID,
+		change.From,
+	)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *Storage) GetAdsByStatus(ctx context.Context, status string, offset, limit int) ([]*model.AdWithAuthor, error) {
+	query := `
+		SELECT
+			a.id,
+			a.title,
+			a.description,
+			a.image_url,
+			COALESCE(i.thumbnail_url, ''),
+			COALESCE(i.medium_url, ''),
+			a.price,
+			a.currency,
+			a.author_id,
+			COALESCE(a.category_id, 0),
+			a.status,
+			COALESCE(a.rejection_reason, ''),
+			a.policy_matches,
+			a.created_at,
+			a.updated_at,
+			u.login as author_login
+		FROM ads a
+		JOIN users u ON a.author_id = u.id
+		LEFT JOIN images i ON i.url = a.image_url
+		WHERE a.status = $1
+		ORDER BY a.updated_at ASC, a.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ads []*model.AdWithAuthor
+	for rows.Next() {
+		var ad model.AdWithAuthor
+		if err := rows.Scan(
+			&ad.ID,
+			&ad.Title,
+			&ad.Description,
+			&ad.ImageURL,
+			&ad.ImageVariants.Thumbnail,
+			&ad.ImageVariants.Medium,
+			&ad.Price,
+			&ad.Currency,
+			&ad.AuthorID,
+			&ad.CategoryID,
+			&ad.Status,
+			&ad.RejectionReason,
+			pq.Array(&ad.PolicyMatches),
+			&ad.CreatedAt,
+			&ad.UpdatedAt,
+			&ad.AuthorLogin,
+		); err != nil {
+			return nil, err
+		}
+		ads = append(ads, &ad)
+	}
+
+	return ads, rows.Err()
+}
+
+func (s *Storage) CountAdsByStatus(ctx context.Context, status string) (int64, error) {
+	var total int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM ads WHERE status = $1`, status).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const profileColumns = `u.id, u.login, u.role, u.display_name, u.about, u.avatar_url, u.location, ` +
+	`COALESCE(u.email, ''), u.email_verified_at, ` +
+	authorRating + `, u.reviews_count, u.created_at`
+
+func (s *Storage) GetProfile(ctx context.Context, login string) (*model.Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM users u WHERE u.login = $1 AND u.deleted_at IS NULL`
+	return scanProfile(s.db.QueryRowContext(ctx, query, login))
+}
+
+func (s *Storage) GetProfileByID(ctx context.Context, id int64) (*model.Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM users u WHERE u.id = $1 AND u.deleted_at IS NULL`
+	return scanProfile(s.db.QueryRowContext(ctx, query, id))
+}
+
+func (s *Storage) UpdateProfile(ctx context.Context, profile *model.Profile) error {
+	query := `
+		UPDATE users
+		SET display_name = $2, about = $3, avatar_url = $4, location = $5
+		WHERE id = $1
+	`
+	res, err := s.db.ExecContext(
+		ctx,
+		query,
+		profile.ID,
+		profile.DisplayName,
+		profile.About,
+		profile.AvatarURL,
+		profile.Location,
+	)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func scanProfile(row *sql.Row) (*model.Profile, error) {
+	var profile model.Profile
+	err := row.Scan(
+		&profile.ID,
+		&profile.Login,
+		&profile.Role,
+		&profile.DisplayName,
+		&profile.About,
+		&profile.AvatarURL,
+		&profile.Location,
+		&profile.Email,
+		&profile.EmailVerifiedAt,
+		&profile.Rating,
+		&profile.ReviewsCount,
+		&profile.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+	"github.com/lib/pq"
+)
+
+func (s *Storage) CreateReport(ctx context.Context, report *model.Report) error {
+	query := `
+		INSERT INTO reports (ad_id, reporter_id, reason, comment, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (ad_id, reporter_id) WHERE status = 'open' DO NOTHING
+		RETURNING id
+	`
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		report.AdID,
+		report.ReporterID,
+		report.Reason,
+		report.Comment,
+		report.Status,
+		report.CreatedAt,
+	).Scan(&report.ID)
+}
+
+func (s *Storage) CountOpenReports(ctx context.Context, adID int64) (int64, error) {
+	var total int64
+	query := `SELECT COUNT(*) FROM reports WHERE ad_id = $1 AND status = 'open'`
+	if err := s.db.QueryRowContext(ctx, query, adID).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+func (s *Storage) HideAd(ctx context.Context, adID int64, hiddenAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE ads SET hidden_at = $2 WHERE id = $1 AND hidden_at IS NULL`, adID, hiddenAt)
+	return err
+}
+
+func (s *Storage) CloseAdReports(ctx context.Context, adID int64, status string, closedBy int64, closedAt time.Time, unhide bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE reports
+		SET status = $2, closed_by = $3, closed_at = $4
+		WHERE ad_id = $1 AND status = 'open'
+	`
+	if _, err := tx.ExecContext(ctx, query, adID, status, closedBy, closedAt); err != nil {
+		return err
+	}
+
+	if unhide {
+		if _, err := tx.ExecContext(ctx, `UPDATE ads SET hidden_at = NULL WHERE id = $1`, adID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) GetAdReports(ctx context.Context, adID int64) ([]model.Report, error) {
+	query := `
+		SELECT id, ad_id, reporter_id, reason, comment, status, created_at, COALESCE(closed_by, 0), closed_at
+		FROM reports
+		WHERE ad_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, adID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []model.Report
+	for rows.Next() {
+		var r model.Report
+		if err := rows.Scan(
+			&r.ID,
+			&r.AdID,
+			&r.ReporterID,
+			&r.Reason,
+			&r.Comment,
+			&r.Status,
+			&r.CreatedAt,
+			&r.ClosedBy,
+			&r.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		reports = append(reports, r)
+	}
+
+	return reports, rows.Err()
+}
+
+func (s *Storage) GetReportedAds(ctx context.Context, offset, limit int) ([]*model.ReportedAd, error) {
+	query := `
+		SELECT
+			a.id,
+			a.title,
+			a.description,
+			a.image_url,
+			COALESCE(i.thumbnail_url, ''),
+			COALESCE(i.medium_url, ''),
+			a.price,
+			a.currency,
+			a.author_id,
+			COALESCE(a.category_id, 0),
+			a.status,
+			COALESCE(a.rejection_reason, ''),
+			a.hidden_at,
+			a.created_at,
+			a.updated_at,
+			u.login as author_login,
+			r.open_reports,
+			r.reasons,
+			r.last_reported_at
+		FROM (
+			SELECT ad_id, COUNT(*) AS open_reports, array_agg(DISTINCT reason) AS reasons, MAX(created_at) AS last_reported_at
+			FROM reports
+			WHERE status = 'open'
+			GROUP BY ad_id
+		) r
+		JOIN ads a ON a.id = r.ad_id
+		JOIN users u ON a.author_id = u.id
+		LEFT JOIN images i ON i.url = a.image_url
+		ORDER BY r.open_reports DESC, r.last_reported_at DESC, a.id DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reported []*model.ReportedAd
+	for rows.Next() {
+		var ad model.AdWithAuthor
+		item := &model.ReportedAd{Ad: &ad}
+		if err := rows.Scan(
+			&ad.ID,
+			&ad.Title,
+			&ad.Description,
+			&ad.ImageURL,
+			&ad.ImageVariants.Thumbnail,
+			&ad.ImageVariants.Medium,
+			&ad.Price,
+			&ad.Currency,
+			&ad.AuthorID,
+			&ad.CategoryID,
+			&ad.Status,
+			&ad.RejectionReason,
+			&ad.HiddenAt,
+			&ad.CreatedAt,
+			&ad.UpdatedAt,
+			&ad.AuthorLogin,
+			&item.OpenReports,
+			pq.Array(&item.Reasons),
+			&item.LastReportedAt,
+		); err != nil {
+			return nil, err
+		}
+		reported = append(reported, item)
+	}
+
+	return reported, rows.Err()
+}
+
+func (s *Storage) CountReportedAds(ctx context.Context) (int64, error) {
+	var total int64
+	query := `SELECT COUNT(DISTINCT ad_id) FROM reports WHERE status = 'open'`
+	if err := s.db.QueryRowContext(ctx, query).Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const authorRating = `ROUND(u.rating_total::numeric / NULLIF(u.reviews_count, 0), 2)`
+
+func (s *Storage) CreateReview(ctx context.Context, review *model.Review) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO reviews (ad_id, ad_title, seller_id, reviewer_id, rating, text, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (ad_id, reviewer_id) DO NOTHING
+		RETURNING id
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		review.AdID,
+		review.AdTitle,
+		review.SellerID,
+		review.ReviewerID,
+		review.Rating,
+		review.Text,
+		review.CreatedAt,
+	).Scan(&review.ID)
+	if err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE users
+		SET reviews_count = reviews_count + 1, rating_total = rating_total + $2
+		WHERE id = $1
+	`
+	res, err := tx.ExecContext(ctx, query, review.SellerID, review.Rating)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) GetSellerReviews(ctx context.Context, sellerID int64, offset, limit int) ([]model.Review, error) {
+	query := `
+		SELECT r.id, COALESCE(r.ad_id, 0), r.ad_title, r.seller_id, r.reviewer_id, u.login, r.rating, r.text, r.created_at
+		FROM reviews r
+		JOIN users u ON u.id = r.reviewer_id
+		WHERE r.seller_id = $1
+		ORDER BY r.created_at DESC, r.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, sellerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []model.Review
+	for rows.Next() {
+		var review model.Review
+		if err := rows.Scan(
+			&review.ID,
+			&review.AdID,
+			&review.AdTitle,
+			&review.SellerID,
+			&review.ReviewerID,
+			&review.ReviewerLogin,
+			&review.Rating,
+			&review.Text,
+			&review.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+func (s *Storage) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `
+		WITH expired AS (
+			DELETE FROM revoked_tokens WHERE expires_at < NOW()
+		)
+		INSERT INTO revoked_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO UPDATE SET expires_at = GREATEST(revoked_tokens.expires_at, EXCLUDED.expires_at)
+	`
+	_, err := s.db.ExecContext(ctx, query, jti, expiresAt)
+	return err
+}
+
+func (s *Storage) RevokeUser(ctx context.Context, userID int64, before time.Time) error {
+	query := `
+		INSERT INTO user_token_revocations (user_id, revoked_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = GREATEST(user_token_revocations.revoked_before, EXCLUDED.revoked_before)
+	`
+	_, err := s.db.ExecContext(ctx, query, userID, before)
+	return err
+}
+
+func (s *Storage) IsRevoked(ctx context.Context, jti string, userID int64, issuedAt time.Time) (bool, error) {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND expires_at > NOW())
+			OR EXISTS (SELECT 1 FROM user_token_revocations WHERE user_id = $2 AND revoked_before >= $3)
+	`
+	var revoked bool
+	err := s.db.QueryRowContext(ctx, query, jti, userID, issuedAt).Scan(&revoked)
+	return revoked, err
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/AugustSerenity/marketplace/internal/handler/model/ad"
 	"github.com/AugustSerenity/marketplace/internal/model"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Storage struct {
@@ -21,37 +21,86 @@ func New(db *sql.DB) *Storage {
 
 func (s *Storage) CreateUser(ctx context.Context, user *model.User) error {
 	query := `
-		INSERT INTO users (login, password_hash, created_at)
-		VALUES ($1, $2, $3)
-		RETURNING id
+		INSERT INTO users (login, password_hash, email, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4)
+		RETURNING id, role
 	`
 	return s.db.QueryRowContext(
 		ctx,
 		query,
 		user.Login,
 		user.PasswordHash,
+		user.Email,
 		user.CreatedAt,
-	).Scan(&user.ID)
+	).Scan(&user.ID, &user.Role)
 }
 
 func (s *Storage) GetUserByLogin(ctx context.Context, login string) (*model.User, error) {
 	var user model.User
-	query := `SELECT id, login, password_hash, created_at FROM users WHERE login = $1`
-	err := s.db.QueryRowContext(ctx, query, login).Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt)
+	query := `
+		SELECT id, login, password_hash, role, COALESCE(email, ''), email_verified_at, created_at
+		FROM users
+		WHERE login = $1
+	`
+	err := s.db.QueryRowContext(ctx, query, login).Scan(
+		&user.ID,
+		&user.Login,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.CreatedAt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
+func (s *Storage) GetUserByID(ctx context.Context, id int64) (*model.User, error) {
+	var user model.User
+	query := `
+		SELECT id, login, password_hash, role, COALESCE(email, ''), email_verified_at, created_at
+		FROM users
+		WHERE id = $1
+	`
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Login,
+		&user.PasswordHash,
+		&user.Role,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *Storage) UpdateUserRole(ctx context.Context, id int64, role string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, id)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
 func (s *Storage) CreateAd(ctx context.Context, ad *model.Ad) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO ads (title, description, image_url, price, author_id, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO ads (title, description, image_url, price, author_id, created_at, updated_at, category_id, currency, status, policy_matches)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, 0), $9, $10, $11)
 		RETURNING id
 	`
 
-	return s.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		ad.Title,
@@ -60,8 +109,154 @@ func (s *Storage) CreateAd(ctx context.Context, ad *model.Ad) error {
 		ad.Price,
 		ad.AuthorID,
 		ad.CreatedAt,
+		ad.UpdatedAt,
+		ad.CategoryID,
+		ad.Currency,
+		ad.Status,
+		pq.Array(policyMatches(ad.PolicyMatches)),
 	).Scan(&ad.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceAdImages(ctx, tx, ad.ID, ad.Images); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) GetAdByID(ctx context.Context, id int64) (*model.AdWithAuthor, error) {
+	query := `
+		SELECT
+			a.id,
+			a.title,
+			a.description,
+			a.image_url,
+			COALESCE(i.thumbnail_url, ''),
+			COALESCE(i.medium_url, ''),
+			a.price,
+			a.currency,
+			a.author_id,
+			COALESCE(a.category_id, 0),
+			a.status,
+			COALESCE(a.rejection_reason, ''),
+			a.policy_matches,
+			a.hidden_at,
+			a.created_at,
+			a.updated_at,
+			u.login as author_login
+		FROM ads a
+		JOIN users u ON a.author_id = u.id
+		LEFT JOIN images i ON i.url = a.image_url
+		WHERE a.id = $1
+	`
+
+	var ad model.AdWithAuthor
+	err := s.db.QueryRowContext(ctx, query, id).Scan(
+		&ad.ID,
+		&ad.Title,
+		&ad.Description,
+		&ad.ImageURL,
+		&ad.ImageVariants.Thumbnail,
+		&ad.ImageVariants.Medium,
+		&ad.Price,
+		&ad.Currency,
+		&ad.AuthorID,
+		&ad.CategoryID,
+		&ad.Status,
+		&ad.RejectionReason,
+		pq.Array(&ad.PolicyMatches),
+		&ad.HiddenAt,
+		&ad.CreatedAt,
+		&ad.UpdatedAt,
+		&ad.AuthorLogin,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ad, nil
+}
+
+func (s *Storage) UpdateAd(ctx context.Context, ad *model.Ad, change *model.AdStatusChange) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE ads
+		SET title = $1, description = $2, image_url = $3, price = $4, updated_at = $5, category_id = NULLIF($8, 0), currency = $9,
+			policy_matches = $11
+		WHERE id = $6 AND author_id = $7 AND status = $10
+	`
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		ad.Title,
+		ad.Description,
+		ad.ImageURL,
+		ad.Price,
+		ad.UpdatedAt,
+		ad.ID,
+		ad.AuthorID,
+		ad.CategoryID,
+		ad.Currency,
+		ad.Status,
+		pq.Array(policyMatches(ad.PolicyMatches)),
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	if change != nil {
+		if err := updateAdStatus(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	if ad.Images != nil {
+		if err := replaceAdImages(ctx, tx, ad.ID, ad.Images); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) DeleteAd(ctx context.Context, id, authorID int64) error {
+	query := `DELETE FROM ads WHERE id = $1 AND author_id = $2`
+
+	res, err := s.db.ExecContext(ctx, query, id, authorID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func policyMatches(rules []string) []string {
+	if rules == nil {
+		return []string{}
+	}
+	return rules
+}
+
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
+
 func (s *Storage) GetAds(ctx context.Context, req *ad.ListRequest, userID int64, offset, limit int) ([]*model.AdWithAuthor, error) {
 	query := `
         SELECT 
@@ -69,22 +264,67 @@ func (s *Storage) GetAds(ctx context.Context, req *ad.ListRequest, userID int64,
             a.title, 
             a.description, 
             a.image_url, 
+            COALESCE(i.thumbnail_url, ''),
+            COALESCE(i.medium_url, ''),
             a.price, 
+            a.currency,
+            p.display_price,
             a.author_id,
+            COALESCE(a.category_id, 0),
             a.created_at,
-            u.login as author_login
+            u.login as author_login,
+            ` + authorRating + `,
+            CASE WHEN $7 = '' THEN 0 ELSE ts_rank(a.search_vector, plainto_tsquery('russian', $7)) END AS rank,
+            EXISTS (SELECT 1 FROM favorites f WHERE f.ad_id = a.id AND f.user_id = $14) AS is_favorite
         FROM ads a
         JOIN users u ON a.author_id = u.id
-        WHERE ($1 = 0 OR a.price >= $1)
-        AND ($2 = 0 OR a.price <= $2)
+        LEFT JOIN images i ON i.url = a.image_url
+        JOIN exchange_rates ar ON ar.currency = a.currency
+        JOIN exchange_rates dr ON dr.currency = $13
+        CROSS JOIN LATERAL (SELECT ROUND(a.price * ar.rate / dr.rate, 2) AS display_price) p
+        WHERE a.status = 'published' AND a.hidden_at IS NULL
+        AND ($1::numeric = 0 OR p.display_price >= $1::numeric)
+        AND ($2::numeric = 0 OR p.display_price <= $2::numeric)
+        AND ($7 = '' OR a.search_vector @@ plainto_tsquery('russian', $7))
+        AND ($16 = 0 OR a.author_id = $16)
+        AND ($15::numeric = 0 OR (u.reviews_count > 0 AND u.rating_total >= $15::numeric * u.reviews_count))
+        AND ($12 = 0 OR a.category_id IN (
+            WITH RECURSIVE subtree AS (
+                SELECT id FROM categories WHERE id = $12
+                UNION ALL
+                SELECT c.id FROM categories c JOIN subtree st ON c.parent_id = st.id
+            )
+            SELECT id FROM subtree
+        ))
+        AND ($8::bigint IS NULL
+            OR ($3 = 'price' AND $4 = 'asc' AND (p.display_price, a.id) > ($9::numeric, $8::bigint))
+            OR ($3 = 'price' AND $4 = 'desc' AND (p.display_price, a.id) < ($9::numeric, $8::bigint))
+            OR ($3 = 'created_at' AND $4 = 'asc' AND (a.created_at, a.id) > ($10::timestamp, $8::bigint))
+            OR ($3 = 'created_at' AND $4 = 'desc' AND (a.created_at, a.id) < ($10::timestamp, $8::bigint))
+            OR ($3 = 'relevance' AND $4 = 'asc'
+                AND (ts_rank(a.search_vector, plainto_tsquery('russian', $7)), a.id) > ($11::real, $8::bigint))
+            OR ($3 = 'relevance' AND $4 = 'desc'
+                AND (ts_rank(a.search_vector, plainto_tsquery('russian', $7)), a.id) < ($11::real, $8::bigint)))
         ORDER BY 
-            CASE WHEN $3 = 'price' AND $4 = 'asc' THEN a.price END ASC,
-            CASE WHEN $3 = 'price' AND $4 = 'desc' THEN a.price END DESC,
+            CASE WHEN $3 = 'price' AND $4 = 'asc' THEN p.display_price END ASC,
+            CASE WHEN $3 = 'price' AND $4 = 'desc' THEN p.display_price END DESC,
             CASE WHEN $3 = 'created_at' AND $4 = 'asc' THEN a.created_at END ASC,
-            CASE WHEN $3 = 'created_at' AND $4 = 'desc' THEN a.created_at END DESC
+            CASE WHEN $3 = 'created_at' AND $4 = 'desc' THEN a.created_at END DESC,
+            CASE WHEN $3 = 'relevance' AND $4 = 'asc' THEN ts_rank(a.search_vector, plainto_tsquery('russian', $7)) END ASC,
+            CASE WHEN $3 = 'relevance' AND $4 = 'desc' THEN ts_rank(a.search_vector, plainto_tsquery('russian', $7)) END DESC,
+            CASE WHEN $4 = 'asc' THEN a.id END ASC,
+            CASE WHEN $4 = 'desc' THEN a.id END DESC
         LIMIT $5 OFFSET $6
     `
 
+	var afterID, afterPrice, afterCreatedAt, afterRank interface{}
+	if req.After != nil {
+		afterID = req.After.ID
+		afterPrice = req.After.Price
+		afterCreatedAt = req.After.CreatedAt
+		afterRank = req.After.Rank
+	}
+
 	rows, err := s.db.QueryContext(
 		ctx,
 		query,
@@ -94,6 +334,16 @@ func (s *Storage) GetAds(ctx context.Context, req *ad.ListRequest, userID int64,
 		req.SortOrder,
 		limit,
 		offset,
+		req.Query,
+		afterID,
+		afterPrice,
+		afterCreatedAt,
+		afterRank,
+		req.Category,
+		req.Currency,
+		userID,
+		req.MinAuthorRating,
+		req.AuthorID,
 	)
 	if err != nil {
 		return nil, err
@@ -108,10 +358,18 @@ func (s *Storage) GetAds(ctx context.Context, req *ad.ListRequest, userID int64,
 			&ad.Title,
 			&ad.Description,
 			&ad.ImageURL,
+			&ad.ImageVariants.Thumbnail,
+			&ad.ImageVariants.Medium,
 			&ad.Price,
+			&ad.Currency,
+			&ad.DisplayPrice,
 			&ad.AuthorID,
+			&ad.CategoryID,
 			&ad.CreatedAt,
 			&ad.AuthorLogin,
+			&ad.AuthorRating,
+			&ad.Rank,
+			&ad.IsFavorite,
 		); err != nil {
 			return nil, err
 		}
@@ -120,3 +378,46 @@ func (s *Storage) GetAds(ctx context.Context, req *ad.ListRequest, userID int64,
 
 	return ads, nil
 }
+
+func (s *Storage) CountAds(ctx context.Context, req *ad.ListRequest) (int64, error) {
+	query := `
+        SELECT COUNT(*)
+        FROM ads a
+        JOIN users u ON a.author_id = u.id
+        JOIN exchange_rates ar ON ar.currency = a.currency
+        JOIN exchange_rates dr ON dr.currency = $5
+        CROSS JOIN LATERAL (SELECT ROUND(a.price * ar.rate / dr.rate, 2) AS display_price) p
+        WHERE a.status = 'published' AND a.hidden_at IS NULL
+        AND ($1::numeric = 0 OR p.display_price >= $1::numeric)
+        AND ($2::numeric = 0 OR p.display_price <= $2::numeric)
+        AND ($3 = '' OR a.search_vector @@ plainto_tsquery('russian', $3))
+        AND ($7 = 0 OR a.author_id = $7)
+        AND ($6::numeric = 0 OR (u.reviews_count > 0 AND u.rating_total >= $6::numeric * u.reviews_count))
+        AND ($4 = 0 OR a.category_id IN (
+            WITH RECURSIVE subtree AS (
+                SELECT id FROM categories WHERE id = $4
+                UNION ALL
+                SELECT c.id FROM categories c JOIN subtree st ON c.parent_id = st.id
+            )
+            SELECT id FROM subtree
+        ))
+    `
+
+	var total int64
+	err := s.db.QueryRowContext(
+		ctx,
+		query,
+		req.MinPrice,
+		req.MaxPrice,
+		req.Query,
+		req.Category,
+		req.Currency,
+		req.MinAuthorRating,
+		req.AuthorID,
+	).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/AugustSerenity/marketplace/internal/model"
+)
+
+const threadColumns = `
+	t.id,
+	t.ad_id,
+	a.title,
+	t.buyer_id,
+	bu.login,
+	t.seller_id,
+	su.login,
+	t.created_at,
+	t.last_message_at
+`
+
+const threadJoins = `
+	JOIN ads a ON a.id = t.ad_id
+	JOIN users bu ON bu.id = t.buyer_id
+	JOIN users su ON su.id = t.seller_id
+`
+
+func (s *Storage) CreateThread(ctx context.Context, thread *model.Thread) error {
+	query := `
+		INSERT INTO threads (ad_id, buyer_id, seller_id, created_at, last_message_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (ad_id, buyer_id) DO NOTHING
+		RETURNING id
+	`
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		thread.AdID,
+		thread.BuyerID,
+		thread.SellerID,
+		thread.CreatedAt,
+	).Scan(&thread.ID)
+}
+
+func (s *Storage) GetThread(ctx context.Context, id int64) (*model.Thread, error) {
+	query := `SELECT ` + threadColumns + ` FROM threads t ` + threadJoins + ` WHERE t.id = $1`
+	return scanThread(s.db.QueryRowContext(ctx, query, id))
+}
+
+func (s *Storage) FindThread(ctx context.Context, adID, buyerID int64) (*model.Thread, error) {
+	query := `SELECT ` + threadColumns + ` FROM threads t ` + threadJoins + ` WHERE t.ad_id = $1 AND t.buyer_id = $2`
+	return scanThread(s.db.QueryRowContext(ctx, query, adID, buyerID))
+}
+
+func scanThread(row *sql.Row) (*model.Thread, error) {
+	var thread model.Thread
+	err := row.Scan(
+		&thread.ID,
+		&thread.AdID,
+		&thread.AdTitle,
+		&thread.BuyerID,
+		&thread.BuyerLogin,
+		&thread.SellerID,
+		&thread.SellerLogin,
+		&thread.CreatedAt,
+		&thread.LastMessageAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
+func (s *Storage) CreateMessage(ctx context.Context, msg *model.Message) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO messages (thread_id, sender_id, body, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	if err := tx.QueryRowContext(ctx, query, msg.ThreadID, msg.SenderID, msg.Body, msg.CreatedAt).Scan(&msg.ID); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE threads
+		SET last_message_at = $3,
+			buyer_last_read_id = CASE WHEN buyer_id = $2 THEN $4 ELSE buyer_last_read_id END,
+			seller_last_read_id = CASE WHEN seller_id = $2 THEN $4 ELSE seller_last_read_id END
+		WHERE id = $1
+	`
+	res, err := tx.ExecContext(ctx, query, msg.ThreadID, msg.SenderID, msg.CreatedAt, msg.ID)
+	if err != nil {
+		return err
+	}
+	if err := checkAffected(res); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *Storage) GetMessages(ctx context.Context, threadID, afterID int64, limit int) ([]model.Message, error) {
+	query := `
+		SELECT id, thread_id, sender_id, body, created_at
+		FROM messages
+		WHERE thread_id = $1 AND id > $2
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, threadID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []model.Message
+	for rows.Next() {
+		var msg model.Message
+		if err := rows.Scan(&msg.ID, &msg.ThreadID, &msg.SenderID, &msg.Body, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+func (s *Storage) MarkThreadRead(ctx context.Context, threadID, userID int64) error {
+	query := `
+		UPDATE threads t
+		SET buyer_last_read_id = CASE WHEN t.buyer_id = $2 THEN m.last_id ELSE t.buyer_last_read_id END,
+			seller_last_read_id = CASE WHEN t.seller_id = $2 THEN m.last_id ELSE t.seller_last_read_id END
+		FROM (SELECT COALESCE(MAX(id), 0) AS last_id FROM messages WHERE thread_id = $1) m
+		WHERE t.id = $1
+	`
+	res, err := s.db.ExecContext(ctx, query, threadID, userID)
+	if err != nil {
+		return err
+	}
+	return checkAffected(res)
+}
+
+func (s *Storage) GetUserThreads(ctx context.Context, userID int64, offset, limit int) ([]*model.Thread, error) {
+	query := `
+		SELECT ` + threadColumns + `,
+			lm.id,
+			lm.sender_id,
+			lm.body,
+			lm.created_at,
+			(
+				SELECT COUNT(*) FROM messages m
+				WHERE m.thread_id = t.id AND m.sender_id <> $1
+				AND m.id > CASE WHEN t.buyer_id = $1 THEN t.buyer_last_read_id ELSE t.seller_last_read_id END
+			) AS unread
+		FROM threads t ` + threadJoins + `
+		LEFT JOIN LATERAL (
+			SELECT id, sender_id, body, created_at FROM messages
+			WHERE thread_id = t.id
+			ORDER BY id DESC
+			LIMIT 1
+		) lm ON true
+		WHERE t.buyer_id = $1 OR t.seller_id = $1
+		ORDER BY t.last_message_at DESC, t.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var threads []*model.Thread
+	for rows.Next() {
+		var (
+			thread    model.Thread
+			msgID     sql.NullInt64
+			senderID  sql.NullInt64
+			body      sql.NullString
+			createdAt sql.NullTime
+		)
+		if err := rows.Scan(
+			&thread.ID,
+			&thread.AdID,
+			&thread.AdTitle,
+			&thread.BuyerID,
+			&thread.BuyerLogin,
+			&thread.SellerID,
+			&thread.SellerLogin,
+			&thread.CreatedAt,
+			&thread.LastMessageAt,
+			&msgID,
+			&senderID,
+			&body,
+			&createdAt,
+			&thread.Unread,
+		); err != nil {
+			return nil, err
+		}
+		if msgID.Valid {
+			thread.LastMessage = &model.Message{
+				ID:        msgID.Int64,
+				ThreadID:  thread.ID,
+				SenderID:  senderID.Int64,
+				Body:      body.String,
+				CreatedAt: createdAt.Time,
+			}
+		}
+		threads = append(threads, &thread)
+	}
+
+	return threads, rows.Err()
+}
+
+func (s *Storage) CountUserThreads(ctx context.Context, userID int64) (int64, int64, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM((
+				SELECT COUNT(*) FROM messages m
+				WHERE m.thread_id = t.id AND m.sender_id <> $1
+				AND m.id > CASE WHEN t.buyer_id = $1 THEN t.buyer_last_read_id ELSE t.seller_last_read_id END
+			)), 0)
+		FROM threads t
+		WHERE t.buyer_id = $1 OR t.seller_id = $1
+	`
+
+	var total, unread int64
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(&total, &unread); err != nil {
+		return 0, 0, err
+	}
+	return total, unread, nil
+}
@@ -0,0 +1,6 @@
+ALTER TABLE ads ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('russian', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_ads_search_vector ON ads USING GIN (search_vector);
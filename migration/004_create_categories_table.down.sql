@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_ads_category_id;
+
+ALTER TABLE ads DROP COLUMN IF EXISTS category_id;
+
+DROP INDEX IF EXISTS idx_categories_parent_id;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,22 @@
+CREATE TABLE categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    parent_id INTEGER REFERENCES categories(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+ALTER TABLE ads ADD COLUMN category_id INTEGER REFERENCES categories(id);
+
+CREATE INDEX idx_ads_category_id ON ads(category_id);
+
+INSERT INTO categories (name, slug) VALUES
+    ('Electronics', 'electronics'),
+    ('Books', 'books'),
+    ('Hobbies', 'hobbies');
+
+INSERT INTO categories (name, slug, parent_id) VALUES
+    ('Phones', 'phones', (SELECT id FROM categories WHERE slug = 'electronics')),
+    ('Laptops', 'laptops', (SELECT id FROM categories WHERE slug = 'electronics')),
+    ('Fishing', 'fishing', (SELECT id FROM categories WHERE slug = 'hobbies'));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_images_owner_id;
+
+DROP TABLE IF EXISTS images;
@@ -0,0 +1,2 @@
+ALTER TABLE images DROP COLUMN IF EXISTS medium_url;
+ALTER TABLE images DROP COLUMN IF EXISTS thumbnail_url;
@@ -0,0 +1,2 @@
+ALTER TABLE images ADD COLUMN thumbnail_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN medium_url TEXT NOT NULL DEFAULT '';
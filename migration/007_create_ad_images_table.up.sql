@@ -0,0 +1,11 @@
+CREATE TABLE ad_images (
+    id SERIAL PRIMARY KEY,
+    ad_id INTEGER NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    position INTEGER NOT NULL CHECK (position >= 0),
+    UNIQUE (ad_id, position),
+    UNIQUE (ad_id, url)
+);
+
+INSERT INTO ad_images (ad_id, url, position)
+SELECT id, image_url, 0 FROM ads;
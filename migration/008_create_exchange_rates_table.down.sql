@@ -0,0 +1,3 @@
+ALTER TABLE ads DROP COLUMN IF EXISTS currency;
+
+DROP TABLE IF EXISTS exchange_rates;
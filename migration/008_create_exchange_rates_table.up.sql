@@ -0,0 +1,14 @@
+CREATE TABLE exchange_rates (
+    currency CHAR(3) PRIMARY KEY,
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO exchange_rates (currency, rate) VALUES
+    ('RUB', 1),
+    ('USD', 90),
+    ('EUR', 98);
+
+ALTER TABLE ads ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB' REFERENCES exchange_rates(currency);
+
+CREATE INDEX idx_ads_currency ON ads(currency);
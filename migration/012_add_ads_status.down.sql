@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_ads_status_created_at;
+
+ALTER TABLE ads
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS status_changed_by,
+    DROP COLUMN IF EXISTS rejection_reason,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,8 @@
+ALTER TABLE ads
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published'
+        CHECK (status IN ('pending', 'published', 'rejected', 'archived')),
+    ADD COLUMN rejection_reason TEXT,
+    ADD COLUMN status_changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN status_changed_at TIMESTAMP;
+
+CREATE INDEX idx_ads_status_created_at ON ads(status, created_at);
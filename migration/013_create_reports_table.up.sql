@@ -0,0 +1,18 @@
+CREATE TABLE reports (
+    id SERIAL PRIMARY KEY,
+    ad_id INTEGER NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    reporter_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(32) NOT NULL
+        CHECK (reason IN ('scam', 'prohibited', 'spam', 'offensive', 'wrong_category', 'other')),
+    comment TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'resolved', 'dismissed')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_reports_open_per_user ON reports(ad_id, reporter_id) WHERE status = 'open';
+CREATE INDEX idx_reports_status_ad_id ON reports(status, ad_id);
+
+ALTER TABLE ads ADD COLUMN hidden_at TIMESTAMP;
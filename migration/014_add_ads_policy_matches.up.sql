@@ -0,0 +1 @@
+ALTER TABLE ads ADD COLUMN policy_matches TEXT[] NOT NULL DEFAULT '{}';
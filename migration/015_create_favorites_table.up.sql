@@ -0,0 +1,8 @@
+CREATE TABLE favorites (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ad_id INTEGER NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, ad_id)
+);
+
+CREATE INDEX idx_favorites_user_created_at ON favorites(user_id, created_at DESC);
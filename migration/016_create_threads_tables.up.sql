@@ -0,0 +1,24 @@
+CREATE TABLE threads (
+    id SERIAL PRIMARY KEY,
+    ad_id INTEGER NOT NULL REFERENCES ads(id) ON DELETE CASCADE,
+    buyer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    buyer_last_read_id INTEGER NOT NULL DEFAULT 0,
+    seller_last_read_id INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_message_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (ad_id, buyer_id)
+);
+
+CREATE INDEX idx_threads_buyer_last_message ON threads(buyer_id, last_message_at DESC);
+CREATE INDEX idx_threads_seller_last_message ON threads(seller_id, last_message_at DESC);
+
+CREATE TABLE messages (
+    id SERIAL PRIMARY KEY,
+    thread_id INTEGER NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_messages_thread_id ON messages(thread_id, id);
@@ -0,0 +1,17 @@
+CREATE TABLE reviews (
+    id SERIAL PRIMARY KEY,
+    ad_id INTEGER REFERENCES ads(id) ON DELETE SET NULL,
+    ad_title VARCHAR(100) NOT NULL,
+    seller_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reviewer_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    text TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (ad_id, reviewer_id)
+);
+
+CREATE INDEX idx_reviews_seller_created_at ON reviews(seller_id, created_at DESC);
+
+ALTER TABLE users
+    ADD COLUMN reviews_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN rating_total INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_ads_author_published;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS about,
+    DROP COLUMN IF EXISTS display_name;
@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN display_name VARCHAR(50) NOT NULL DEFAULT '',
+    ADD COLUMN about TEXT NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN location VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_ads_author_published ON ads(author_id, created_at DESC) WHERE status = 'published' AND hidden_at IS NULL;